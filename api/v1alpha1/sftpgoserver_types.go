@@ -24,12 +24,32 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// LDAPAuthPluginName is the name of the plugin implementing auth.ldap, which
+// user plugins cannot use
+const LDAPAuthPluginName = "ldap-auth"
+
+// Ports used by SFTPGO when none is set
+const (
+	DefaultSFTPPort int32 = 2022
+	DefaultWebPort  int32 = 8080
+	DefaultFTPPort  int32 = 2121
+)
+
 // SftpGoServerSpec defines the desired state of SftpGoServer
+// +kubebuilder:validation:XValidation:rule="(has(self.sftpPort) ? self.sftpPort : 2022) != (has(self.webPort) ? self.webPort : 8080)",message="sftpPort and webPort must differ"
+// +kubebuilder:validation:XValidation:rule="!has(self.storageBackend) || !(self.storageBackend in ['mysql', 'postgres']) || has(self.database)",message="database is required by the mysql and postgres storage backends"
+// +kubebuilder:validation:XValidation:rule="!has(self.userManagement) || self.userManagement != 'exclusive' || has(self.adminSecretRef)",message="adminSecretRef is required by exclusive user management"
 type SftpGoServerSpec struct {
 	// Image is the SFTPGO container image (default: docker.io/drakkan/sftpgo:latest)
 	// +optional
 	Image string `json:"image,omitempty"`
 
+	// ImageVariant selects the default SFTPGO image flavour when Image is not set:
+	// standard, alpine, distroless or plugins (default: standard)
+	// +optional
+	// +kubebuilder:validation:Enum=standard;alpine;distroless;plugins
+	ImageVariant string `json:"imageVariant,omitempty"`
+
 	// ImagePullPolicy is the image pull policy
 	// +optional
 	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
@@ -59,6 +79,19 @@ type SftpGoServerSpec struct {
 	// +kubebuilder:validation:Maximum=65535
 	WebPort int32 `json:"webPort,omitempty"`
 
+	// Service configuration
+	// +optional
+	Service *ServiceConfig `json:"service,omitempty"`
+
+	// Ingress exposing the WebAdmin/WebClient and REST API
+	// +optional
+	Ingress *IngressConfig `json:"ingress,omitempty"`
+
+	// ProxyProtocol configures the PROXY protocol for every SFTPGO binding, needed
+	// to preserve client IPs behind load balancers that send a PROXY header
+	// +optional
+	ProxyProtocol *ProxyProtocolConfig `json:"proxyProtocol,omitempty"`
+
 	// Data Volume configuration
 	// +optional
 	DataVolume *VolumeConfig `json:"dataVolume,omitempty"`
@@ -92,6 +125,415 @@ type SftpGoServerSpec struct {
 	// keys for the SFTPGO admin API (used by SftpGoUser controller to manage users)
 	// +optional
 	AdminSecretRef *corev1.LocalObjectReference `json:"adminSecretRef,omitempty"`
+
+	// AllowedUserNamespaces selects the namespaces whose SftpGoUsers may
+	// reference this server. Users in the server namespace are always
+	// allowed, users in other namespaces only when this selects their
+	// namespace: unset allows none, an empty selector allows all.
+	// +optional
+	AllowedUserNamespaces *metav1.LabelSelector `json:"allowedUserNamespaces,omitempty"`
+
+	// AllowCrossNamespaceAdoption lets the SftpGoUsers of other namespaces
+	// take over SFTPGO users that already exist with the adopt policy.
+	// Without it only users in the server namespace can adopt.
+	// +optional
+	AllowCrossNamespaceAdoption bool `json:"allowCrossNamespaceAdoption,omitempty"`
+
+	// UserManagement is shared (default) to leave alone the SFTPGO users no
+	// SftpGoUser manages, or exclusive to report them and delete them
+	// according to userPruning. Requires AdminSecretRef.
+	// +optional
+	// +kubebuilder:validation:Enum=shared;exclusive
+	UserManagement string `json:"userManagement,omitempty"`
+
+	// UserPruning configures how exclusive user management handles the users
+	// no SftpGoUser manages
+	// +optional
+	UserPruning *UserPruningConfig `json:"userPruning,omitempty"`
+
+	// Plugins to install into the pod and load through the SFTPGO plugin system
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Plugins []PluginConfig `json:"plugins,omitempty"`
+
+	// Auth configures server level authentication (OIDC, external auth hook, LDAP)
+	// +optional
+	Auth *AuthConfig `json:"auth,omitempty"`
+
+	// Logging configures SFTPGO logs and the optional log shipping sidecar
+	// +optional
+	Logging *LoggingConfig `json:"logging,omitempty"`
+}
+
+// LoggingConfig defines the SFTPGO logging settings
+type LoggingConfig struct {
+	// Level: debug, info, warn, error (default: info)
+	// +optional
+	// +kubebuilder:validation:Enum=debug;info;warn;error
+	Level string `json:"level,omitempty"`
+
+	// Format: console (human readable, written to stdout) or json (written to a
+	// file and streamed by the log shipper sidecar) (default: console)
+	// +optional
+	// +kubebuilder:validation:Enum=console;json
+	Format string `json:"format,omitempty"`
+
+	// UTCTime logs timestamps in UTC
+	// +optional
+	UTCTime bool `json:"utcTime,omitempty"`
+
+	// MaxSize in MB of the json log file before rotation (default: 10)
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxSize int `json:"maxSize,omitempty"`
+
+	// MaxBackups is the number of rotated json log files kept (default: 5)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxBackups int `json:"maxBackups,omitempty"`
+
+	// Audit selects the audit entries shipped by the log shipper (ignored with console format)
+	// +optional
+	Audit *AuditLogConfig `json:"audit,omitempty"`
+
+	// Shipper is the log shipping sidecar. Defaults to the fluent-bit preset
+	// writing to stdout when format is json
+	// +optional
+	Shipper *LogShipperConfig `json:"shipper,omitempty"`
+}
+
+// AuditLogConfig toggles the SFTPGO audit log entries
+type AuditLogConfig struct {
+	// Transfers ships upload and download entries (default: true)
+	// +optional
+	Transfers *bool `json:"transfers,omitempty"`
+
+	// Commands ships filesystem command entries (rename, remove, mkdir, SSH commands, ...) (default: true)
+	// +optional
+	Commands *bool `json:"commands,omitempty"`
+}
+
+// LogShipperConfig defines the log shipping sidecar
+type LogShipperConfig struct {
+	// Preset of the sidecar: fluent-bit
+	// +kubebuilder:validation:Enum=fluent-bit
+	Preset string `json:"preset"`
+
+	// Image of the sidecar (default: cr.fluentbit.io/fluent/fluent-bit:3.2)
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Output: stdout, forward (Fluentd/Fluent Bit aggregator) or http (default: stdout)
+	// +optional
+	// +kubebuilder:validation:Enum=stdout;forward;http
+	Output string `json:"output,omitempty"`
+
+	// Host of the forward or http output
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Port of the forward or http output
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+
+	// URI of the http output
+	// +optional
+	URI string `json:"uri,omitempty"`
+
+	// TLS enables TLS for the forward or http output
+	// +optional
+	TLS bool `json:"tls,omitempty"`
+
+	// Resources of the sidecar container
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// IngressConfig defines the Ingress for the web port
+type IngressConfig struct {
+	// Host name served by the Ingress
+	// +kubebuilder:validation:Required
+	Host string `json:"host"`
+
+	// IngressClassName of the Ingress
+	// +optional
+	ClassName *string `json:"className,omitempty"`
+
+	// Annotations added to the Ingress
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// TLSSecretName is the secret holding the TLS certificate for Host
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+}
+
+// AuthConfig defines server level authentication
+// +kubebuilder:validation:XValidation:rule="!(has(self.externalAuthHook) && has(self.ldap))",message="externalAuthHook and ldap are mutually exclusive"
+type AuthConfig struct {
+	// OIDC login for WebAdmin and WebClient
+	// +optional
+	OIDC *OIDCConfig `json:"oidc,omitempty"`
+
+	// ExternalAuthHook delegates user authentication to a program or HTTP endpoint
+	// +optional
+	ExternalAuthHook *ExternalAuthHookConfig `json:"externalAuthHook,omitempty"`
+
+	// LDAP authentication through the SFTPGO LDAP/Active Directory auth plugin
+	// +optional
+	LDAP *LDAPAuthConfig `json:"ldap,omitempty"`
+}
+
+// OIDCConfig defines the OpenID Connect settings
+type OIDCConfig struct {
+	// ConfigURL is the issuer URL, used for OpenID discovery
+	// +kubebuilder:validation:Required
+	ConfigURL string `json:"configURL"`
+
+	// ClientID registered with the identity provider
+	// +kubebuilder:validation:Required
+	ClientID string `json:"clientID"`
+
+	// ClientSecretRef references the key holding the client secret
+	// +kubebuilder:validation:Required
+	ClientSecretRef SecretRef `json:"clientSecretRef"`
+
+	// RedirectBaseURL is the external base URL of SFTPGO. Defaults to the
+	// ingress host (https if the ingress has TLS)
+	// +optional
+	RedirectBaseURL string `json:"redirectBaseURL,omitempty"`
+
+	// UsernameField is the ID token claim used as username (default: preferred_username)
+	// +optional
+	UsernameField string `json:"usernameField,omitempty"`
+
+	// RoleField is the ID token claim used to tell admins from users
+	// +optional
+	RoleField string `json:"roleField,omitempty"`
+
+	// ImplicitRoles grants the admin or user role from the login page used
+	// +optional
+	ImplicitRoles bool `json:"implicitRoles,omitempty"`
+
+	// Scopes requested (default: openid, profile, email)
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// ExternalAuthHookConfig defines the external authentication hook
+type ExternalAuthHookConfig struct {
+	// Hook is the absolute path of a program or an HTTP URL
+	// +kubebuilder:validation:Required
+	Hook string `json:"hook"`
+
+	// Scope is a bitmask of authentications delegated to the hook:
+	// 1=password, 2=public key, 4=keyboard interactive, 8=TLS certificate (default: all)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=15
+	Scope int `json:"scope,omitempty"`
+}
+
+// LDAPAuthConfig defines the settings of the LDAP/Active Directory auth plugin
+type LDAPAuthConfig struct {
+	// URLs of the LDAP servers (e.g. ldaps://ldap.example.com:636)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	URLs []string `json:"urls"`
+
+	// BaseDN for user searches
+	// +kubebuilder:validation:Required
+	BaseDN string `json:"baseDN"`
+
+	// BindDN used to search users
+	// +optional
+	BindDN string `json:"bindDN,omitempty"`
+
+	// BindPasswordRef references the key holding the bind password
+	// +optional
+	BindPasswordRef *SecretRef `json:"bindPasswordRef,omitempty"`
+
+	// SearchQuery to find a user, %username% is replaced with the login name
+	// (default: (&(objectClass=user)(sAMAccountName=%username%)))
+	// +optional
+	SearchQuery string `json:"searchQuery,omitempty"`
+
+	// GroupAttributes read from the user entry to map SFTPGO groups
+	// +optional
+	GroupAttributes []string `json:"groupAttributes,omitempty"`
+
+	// UsersBaseDir is the base directory for the home of users created on first login
+	// +optional
+	UsersBaseDir string `json:"usersBaseDir,omitempty"`
+
+	// StartTLS upgrades ldap:// connections to TLS
+	// +optional
+	StartTLS bool `json:"startTLS,omitempty"`
+
+	// SkipTLSVerify disables the LDAP server certificate verification
+	// +optional
+	SkipTLSVerify bool `json:"skipTLSVerify,omitempty"`
+
+	// Scope is a bitmask of authentications handled by LDAP:
+	// 1=password, 4=keyboard interactive (default: 5)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=15
+	Scope int `json:"scope,omitempty"`
+
+	// Image containing the auth plugin. When empty the plugin shipped in the
+	// SFTPGO plugins image is used
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Binary is the path of the auth plugin (default: /usr/local/bin/sftpgo-plugin-auth)
+	// +optional
+	Binary string `json:"binary,omitempty"`
+}
+
+// PluginConfig defines an SFTPGO plugin
+type PluginConfig struct {
+	// Name of the plugin, used for the installed binary and the init container.
+	// ldap-auth is reserved for the plugin implementing auth.ldap.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=48
+	// +kubebuilder:validation:XValidation:rule="self != 'ldap-auth'",message="ldap-auth is reserved for the LDAP authentication plugin"
+	Name string `json:"name"`
+
+	// Type of the plugin: notifier, kms, auth, eventsearcher, ipfilter
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=notifier;kms;auth;eventsearcher;ipfilter
+	Type string `json:"type"`
+
+	// Image containing the plugin binary. When set, an init container runs cp
+	// in this image to copy Binary into a volume shared with SFTPGO, so the
+	// image must provide cp: images built FROM scratch or distroless images
+	// are not supported. When empty, Binary must already exist in the SFTPGO
+	// image (e.g. the plugins variant)
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// ImagePullPolicy for the plugin image
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// Binary is the path of the plugin executable
+	// +kubebuilder:validation:Required
+	Binary string `json:"binary"`
+
+	// Args passed to the plugin executable
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// SHA256Sum of the plugin executable, verified by SFTPGO before loading
+	// +optional
+	SHA256Sum string `json:"sha256sum,omitempty"`
+
+	// AutoMTLS enables automatic mTLS between SFTPGO and the plugin
+	// +optional
+	AutoMTLS bool `json:"autoMTLS,omitempty"`
+
+	// Env variables added to the SFTPGO container and passed through to the plugin
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Notifier options (type notifier)
+	// +optional
+	Notifier *PluginNotifierOptions `json:"notifier,omitempty"`
+
+	// KMS options (type kms)
+	// +optional
+	KMS *PluginKMSOptions `json:"kms,omitempty"`
+
+	// Auth options (type auth)
+	// +optional
+	Auth *PluginAuthOptions `json:"auth,omitempty"`
+}
+
+// PluginNotifierOptions defines the events forwarded to a notifier plugin
+type PluginNotifierOptions struct {
+	// Filesystem events: upload, download, delete, rename, mkdir, rmdir, ssh_cmd, ...
+	// +optional
+	FSEvents []string `json:"fsEvents,omitempty"`
+
+	// Provider events: add, update, delete
+	// +optional
+	ProviderEvents []string `json:"providerEvents,omitempty"`
+
+	// Provider objects: user, folder, group, admin, api_key, share, ...
+	// +optional
+	ProviderObjects []string `json:"providerObjects,omitempty"`
+
+	// Maximum time in seconds to retry a failed notification
+	// +optional
+	RetryMaxTime int `json:"retryMaxTime,omitempty"`
+
+	// Maximum number of queued notifications waiting for a retry
+	// +optional
+	RetryQueueMaxSize int `json:"retryQueueMaxSize,omitempty"`
+}
+
+// PluginKMSOptions defines the options for a kms plugin
+type PluginKMSOptions struct {
+	// Scheme handled by the plugin (e.g. awskms, gcpkms, azurekeyvault, vaulttransit)
+	// +kubebuilder:validation:Required
+	Scheme string `json:"scheme"`
+
+	// EncryptedStatus is the secret status used for data encrypted by the plugin
+	// +optional
+	EncryptedStatus string `json:"encryptedStatus,omitempty"`
+}
+
+// PluginAuthOptions defines the options for an auth plugin
+type PluginAuthOptions struct {
+	// Scope is a bitmask of authentications handled by the plugin:
+	// 1=password, 2=public key, 4=keyboard interactive, 8=TLS certificate
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=15
+	Scope int `json:"scope"`
+}
+
+// ServiceConfig defines the Service exposing SFTPGO
+type ServiceConfig struct {
+	// Type of the Service (default: ClusterIP)
+	// +optional
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Annotations added to the Service (e.g. load balancer settings)
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// ExternalTrafficPolicy for NodePort and LoadBalancer services
+	// +optional
+	// +kubebuilder:validation:Enum=Cluster;Local
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+
+	// LoadBalancerSourceRanges restricts the client IPs allowed by the load balancer
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+}
+
+// ProxyProtocolConfig defines the PROXY protocol settings
+type ProxyProtocolConfig struct {
+	// Mode: off, optional (header used when sent by an allowed proxy) or
+	// required (connections without a header from an allowed proxy are refused)
+	// +kubebuilder:validation:Enum=off;optional;required
+	Mode string `json:"mode"`
+
+	// Allowed lists the IP addresses or CIDR networks of the proxies allowed
+	// to send the PROXY header
+	// +optional
+	Allowed []string `json:"allowed,omitempty"`
+
+	// Skipped lists the IP addresses or CIDR networks that never send the
+	// PROXY header (e.g. health checks) and are accepted without it
+	// +optional
+	Skipped []string `json:"skipped,omitempty"`
 }
 
 // VolumeConfig defines the data volume configuration
@@ -157,6 +599,125 @@ type SFTPGOConfig struct {
 	// HTTP settings
 	// +optional
 	HTTP *HTTPConfig `json:"http,omitempty"`
+
+	// Defender (automatic blocking of misbehaving hosts) settings
+	// +optional
+	Defender *DefenderConfig `json:"defender,omitempty"`
+
+	// Rate limiters
+	// +optional
+	RateLimiters []RateLimiterConfig `json:"rateLimiters,omitempty"`
+}
+
+// DefenderConfig defines the SFTPGO defender settings
+type DefenderConfig struct {
+	// Enable the defender
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Driver: memory or provider (provider shares the state between replicas)
+	// +optional
+	// +kubebuilder:validation:Enum=memory;provider
+	Driver string `json:"driver,omitempty"`
+
+	// Ban time in minutes (default: 30)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	BanTime int `json:"banTime,omitempty"`
+
+	// Percentage increment of the ban time for hosts banned again (default: 50)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	BanTimeIncrement int `json:"banTimeIncrement,omitempty"`
+
+	// Score threshold after which a host is banned (default: 15)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Threshold int `json:"threshold,omitempty"`
+
+	// Score for a login attempt with an invalid user (default: 2)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ScoreInvalid int `json:"scoreInvalid,omitempty"`
+
+	// Score for a failed login attempt with a valid user (default: 1)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ScoreValid int `json:"scoreValid,omitempty"`
+
+	// Score for a rate limit exceeded event (default: 3)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ScoreLimitExceeded int `json:"scoreLimitExceeded,omitempty"`
+
+	// Score for a client disconnecting without authenticating (default: 0)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ScoreNoAuth int `json:"scoreNoAuth,omitempty"`
+
+	// Observation time in minutes for the score of a host (default: 30)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ObservationTime int `json:"observationTime,omitempty"`
+
+	// Soft limit of hosts tracked by the memory driver (default: 100)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	EntriesSoftLimit int `json:"entriesSoftLimit,omitempty"`
+
+	// Hard limit of hosts tracked by the memory driver (default: 150)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	EntriesHardLimit int `json:"entriesHardLimit,omitempty"`
+
+	// Safelist of IP addresses or CIDR networks never banned
+	// +optional
+	Safelist []string `json:"safelist,omitempty"`
+
+	// Blocklist of IP addresses or CIDR networks always refused
+	// +optional
+	Blocklist []string `json:"blocklist,omitempty"`
+}
+
+// RateLimiterConfig defines an SFTPGO rate limiter
+type RateLimiterConfig struct {
+	// Average number of allowed requests per period
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	Average int64 `json:"average"`
+
+	// Period in milliseconds (default: 1000)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Period int64 `json:"period,omitempty"`
+
+	// Burst is the maximum number of requests allowed at once (default: 1)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Burst int `json:"burst,omitempty"`
+
+	// Type: global (one limiter for all clients) or source (one per client IP) (default: source)
+	// +optional
+	// +kubebuilder:validation:Enum=global;source
+	Type string `json:"type,omitempty"`
+
+	// Protocols the limiter applies to: SSH, FTP, DAV, HTTP (default: all)
+	// +optional
+	Protocols []string `json:"protocols,omitempty"`
+
+	// GenerateDefenderEvents adds a defender score when the limit is exceeded
+	// +optional
+	GenerateDefenderEvents bool `json:"generateDefenderEvents,omitempty"`
+
+	// Soft limit of tracked sources for source limiters (default: 100)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	EntriesSoftLimit int `json:"entriesSoftLimit,omitempty"`
+
+	// Hard limit of tracked sources for source limiters (default: 150)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	EntriesHardLimit int `json:"entriesHardLimit,omitempty"`
 }
 
 // CommonConfig defines common SFTPGO settings
@@ -186,6 +747,8 @@ type SFTPConfig struct {
 
 	// Port (default: 2022)
 	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
 	Port int32 `json:"port,omitempty"`
 
 	// Host keys (list of paths to SSH host keys)
@@ -217,6 +780,8 @@ type FTPConfig struct {
 
 	// Port (default: 2121)
 	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
 	Port int32 `json:"port,omitempty"`
 
 	// Passive port range
@@ -229,9 +794,14 @@ type FTPConfig struct {
 }
 
 // PortRange defines a port range
+// +kubebuilder:validation:XValidation:rule="self.start <= self.end",message="start must not be greater than end"
 type PortRange struct {
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
 	Start int32 `json:"start"`
-	End   int32 `json:"end"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	End int32 `json:"end"`
 }
 
 // WebDAVConfig defines WebDAV server settings
@@ -242,6 +812,8 @@ type WebDAVConfig struct {
 
 	// Port (default: 8080)
 	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
 	Port int32 `json:"port,omitempty"`
 
 	// Enable HTTPS
@@ -265,6 +837,8 @@ type HTTPConfig struct {
 
 	// Port (default: 8080)
 	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
 	Port int32 `json:"port,omitempty"`
 
 	// Enable HTTPS
@@ -300,6 +874,72 @@ type SftpGoServerStatus struct {
 
 	// Service ports
 	Ports ServicePorts `json:"ports,omitempty"`
+
+	// Defender summarises the hosts currently banned by the SFTPGO defender
+	// +optional
+	Defender *DefenderStatus `json:"defender,omitempty"`
+
+	// UnmanagedUsers summarises the SFTPGO users no SftpGoUser manages, with
+	// exclusive user management
+	// +optional
+	UnmanagedUsers *UnmanagedUsersStatus `json:"unmanagedUsers,omitempty"`
+}
+
+// UserPruningConfig configures the pruning of unmanaged SFTPGO users
+type UserPruningConfig struct {
+	// DryRun only reports the unmanaged users, without deleting them
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// ProtectedUsers are usernames, or shell patterns, never deleted
+	// +optional
+	ProtectedUsers []string `json:"protectedUsers,omitempty"`
+
+	// Interval between two scans of the SFTPGO users (default: 10m)
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// UnmanagedUsersStatus summarises the SFTPGO users no SftpGoUser manages
+type UnmanagedUsersStatus struct {
+	// Count is the number of unmanaged users found by the last scan,
+	// protected users included
+	Count int `json:"count"`
+
+	// Users lists the unmanaged users left in SFTPGO (truncated)
+	// +optional
+	Users []string `json:"users,omitempty"`
+
+	// Deleted is the number of users deleted by the last scan
+	// +optional
+	Deleted int `json:"deleted,omitempty"`
+
+	// LastChecked is the last time the users were scanned
+	// +optional
+	LastChecked *metav1.Time `json:"lastChecked,omitempty"`
+}
+
+// DefenderStatus summarises the SFTPGO defender state
+type DefenderStatus struct {
+	// BannedHosts is the number of hosts currently banned
+	BannedHosts int `json:"bannedHosts"`
+
+	// Hosts lists the banned hosts (truncated to the most recent bans)
+	// +optional
+	Hosts []BannedHost `json:"hosts,omitempty"`
+
+	// LastChecked is the last time the defender was queried
+	// +optional
+	LastChecked *metav1.Time `json:"lastChecked,omitempty"`
+}
+
+// BannedHost is a host banned by the SFTPGO defender
+type BannedHost struct {
+	IP string `json:"ip"`
+
+	// BanUntil is the time the ban expires
+	// +optional
+	BanUntil *metav1.Time `json:"banUntil,omitempty"`
 }
 
 // ServicePorts defines the service ports
@@ -315,6 +955,7 @@ type ServicePorts struct {
 // +kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".spec.replicas"
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.readyReplicas"
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Banned",type="integer",JSONPath=".status.defender.bannedHosts",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // SftpGoServer is the Schema for the sftpgoservers API
@@ -24,12 +24,17 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // SftpGoUserSpec defines the desired state of SftpGoUser
+// +kubebuilder:validation:XValidation:rule="!has(self.password) || !has(self.passwordSecretRef)",message="password and passwordSecretRef are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.passwordHashSecretRef) || (!has(self.password) && !has(self.passwordSecretRef))",message="passwordHashSecretRef cannot be used with password or passwordSecretRef"
 type SftpGoUserSpec struct {
-	// Username is the SFTPGO username
+	// Username is the SFTPGO username. It cannot change: create a new
+	// SftpGoUser to rename a user.
 	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="username is immutable"
 	Username string `json:"username"`
 
-	// Password is the user's password (required if not using public key)
+	// Password is the user's password (required if not using public key).
+	// It is stored in clear text in the resource, prefer passwordSecretRef.
 	// +optional
 	Password string `json:"password,omitempty"`
 
@@ -37,14 +42,26 @@ type SftpGoUserSpec struct {
 	// +optional
 	PasswordSecretRef *SecretRef `json:"passwordSecretRef,omitempty"`
 
+	// PasswordHashSecretRef is a reference to a secret containing a bcrypt
+	// ($2a$), argon2id or pbkdf2 hash of the password, so that the plain
+	// password never needs to exist in the cluster
+	// +optional
+	PasswordHashSecretRef *SecretRef `json:"passwordHashSecretRef,omitempty"`
+
 	// PublicKeys is a list of public keys for SSH authentication
 	// +optional
+	// +kubebuilder:validation:items:Pattern=`^(ssh-(rsa|dss|ed25519)|ecdsa-sha2-nistp(256|384|521)|sk-(ssh-ed25519|ecdsa-sha2-nistp256)@openssh\.com) [A-Za-z0-9+/]+={0,2}( .*)?$`
 	PublicKeys []string `json:"publicKeys,omitempty"`
 
 	// PublicKeysSecretRef is a reference to a secret containing public keys
 	// +optional
 	PublicKeysSecretRef *SecretRef `json:"publicKeysSecretRef,omitempty"`
 
+	// GeneratedCredentials requests a password and/or SSH keypair generated
+	// by the operator and stored in an owned Secret
+	// +optional
+	GeneratedCredentials *GeneratedCredentials `json:"generatedCredentials,omitempty"`
+
 	// Email is the user's email address
 	// +optional
 	Email string `json:"email,omitempty"`
@@ -56,6 +73,7 @@ type SftpGoUserSpec struct {
 
 	// HomeDir is the user's home directory
 	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^/`
 	HomeDir string `json:"homeDir"`
 
 	// VirtualFolders defines virtual folders for the user
@@ -64,8 +82,16 @@ type SftpGoUserSpec struct {
 
 	// Permissions defines the user's permissions
 	// +optional
+	// +kubebuilder:validation:items:Enum="*";list;download;upload;overwrite;delete;delete_files;delete_dirs;rename;rename_files;rename_dirs;create_dirs;create_symlinks;chmod;chown;chtimes;copy
 	Permissions []string `json:"permissions,omitempty"`
 
+	// DirectoryPermissions overrides the permissions and restricts the file
+	// patterns of directories below the home dir
+	// +optional
+	// +listType=map
+	// +listMapKey=path
+	DirectoryPermissions []DirectoryPermissions `json:"directoryPermissions,omitempty"`
+
 	// Quota defines storage quota (in bytes)
 	// +optional
 	Quota *Quota `json:"quota,omitempty"`
@@ -74,7 +100,7 @@ type SftpGoUserSpec struct {
 	// +optional
 	BandwidthLimits *BandwidthLimits `json:"bandwidthLimits,omitempty"`
 
-	// Upload/Download limits (in bytes/sec)
+	// Upload/Download limits (in bytes/sec). Burst is not supported by SFTPGO.
 	// +optional
 	RateLimits *RateLimits `json:"rateLimits,omitempty"`
 
@@ -82,22 +108,32 @@ type SftpGoUserSpec struct {
 	// +optional
 	MaxSessions int `json:"maxSessions,omitempty"`
 
-	// Concurrent transfers limit
+	// Concurrent transfers limit. Not supported by SFTPGO, use MaxSessions.
 	// +optional
 	MaxConcurrentTransfers int `json:"maxConcurrentTransfers,omitempty"`
 
 	// Allowed IP addresses (CIDR notation)
 	// +optional
+	// +kubebuilder:validation:MaxItems=100
+	// +kubebuilder:validation:items:MaxLength=64
 	AllowedIP []string `json:"allowedIP,omitempty"`
 
 	// Denied IP addresses (CIDR notation)
 	// +optional
+	// +kubebuilder:validation:MaxItems=100
+	// +kubebuilder:validation:items:MaxLength=64
 	DeniedIP []string `json:"deniedIP,omitempty"`
 
-	// Protocols allowed (SFTP, FTP, WebDAV, HTTP)
+	// Protocols allowed (SFTP, FTP, WebDAV, HTTP), all when empty
 	// +optional
 	Protocols []string `json:"protocols,omitempty"`
 
+	// DisconnectOnRestriction closes the active sessions of the user when its
+	// permissions, allowed IPs or protocols are restricted. Sessions are
+	// always closed when the user is disabled or deleted.
+	// +optional
+	DisconnectOnRestriction bool `json:"disconnectOnRestriction,omitempty"`
+
 	// Groups the user belongs to
 	// +optional
 	Groups []string `json:"groups,omitempty"`
@@ -110,6 +146,27 @@ type SftpGoUserSpec struct {
 	// +optional
 	Filesystem *FilesystemConfig `json:"filesystem,omitempty"`
 
+	// HomeDirPolicy seeds the home directory on creation and removes or
+	// archives it on deletion. Requires a local filesystem and a server
+	// dataVolume holding the home directory.
+	// +optional
+	HomeDirPolicy *HomeDirPolicy `json:"homeDirPolicy,omitempty"`
+
+	// ExpiresAt is when the account expires, SFTPGO denies logins afterwards
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// TTL expires the account this long after the SftpGoUser was created.
+	// Cannot be used with expiresAt.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// DeleteAfterExpiry deletes the SftpGoUser, and the SFTPGO user, this
+	// long after the account expired (0s at expiry). Expired users are kept
+	// when unset.
+	// +optional
+	DeleteAfterExpiry *metav1.Duration `json:"deleteAfterExpiry,omitempty"`
+
 	// The SftpGoServer this user belongs to
 	// +kubebuilder:validation:Required
 	ServerRef ServerRef `json:"serverRef"`
@@ -126,6 +183,54 @@ type SecretRef struct {
 	Key string `json:"key"`
 }
 
+// GeneratedCredentials defines the credentials generated for a user
+type GeneratedCredentials struct {
+	// SecretName is the Secret holding the generated credentials, owned by
+	// the SftpGoUser (default: <name>-credentials)
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// Password generates a random password with this policy. Cannot be used
+	// with password or passwordSecretRef.
+	// +optional
+	Password *PasswordPolicy `json:"password,omitempty"`
+
+	// SSHKey generates an ed25519 keypair. Only the public key is sent to
+	// SFTPGO, in addition to publicKeys.
+	// +optional
+	SSHKey bool `json:"sshKey,omitempty"`
+
+	// Rotation regenerates the credentials periodically
+	// +optional
+	Rotation *CredentialRotation `json:"rotation,omitempty"`
+}
+
+// PasswordPolicy defines how passwords are generated
+type PasswordPolicy struct {
+	// Length of the password (default: 24)
+	// +optional
+	// +kubebuilder:validation:Minimum=12
+	// +kubebuilder:validation:Maximum=128
+	Length int `json:"length,omitempty"`
+
+	// Symbols adds punctuation to the letters and digits of the password
+	// +optional
+	Symbols bool `json:"symbols,omitempty"`
+}
+
+// CredentialRotation defines when generated credentials are rotated
+type CredentialRotation struct {
+	// Interval between two rotations (e.g. 2160h for 90 days)
+	// +kubebuilder:validation:Required
+	Interval metav1.Duration `json:"interval"`
+
+	// Overlap keeps the previous public key active in SFTPGO for this long
+	// after a rotation, so that partners can switch keys. SFTPGO users have
+	// a single password, which is replaced immediately.
+	// +optional
+	Overlap metav1.Duration `json:"overlap,omitempty"`
+}
+
 // VirtualFolder defines a virtual folder
 type VirtualFolder struct {
 	// Virtual path
@@ -141,6 +246,34 @@ type VirtualFolder struct {
 	Quota int64 `json:"quota,omitempty"`
 }
 
+// DirectoryPermissions defines the permissions and file patterns of a directory
+type DirectoryPermissions struct {
+	// Path of the directory, relative to the user's root (e.g. /in)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path"`
+
+	// Permissions for the directory and its subdirectories, inherited from
+	// the parent directory when empty
+	// +optional
+	// +kubebuilder:validation:items:Enum="*";list;download;upload;overwrite;delete;delete_files;delete_dirs;rename;rename_files;rename_dirs;create_dirs;create_symlinks;chmod;chown;chtimes;copy
+	Permissions []string `json:"permissions,omitempty"`
+
+	// Shell patterns of the file names allowed in the directory (e.g. *.csv)
+	// +optional
+	AllowedPatterns []string `json:"allowedPatterns,omitempty"`
+
+	// Shell patterns of the file names denied in the directory
+	// +optional
+	DeniedPatterns []string `json:"deniedPatterns,omitempty"`
+
+	// DenyPolicy: default denies access to the denied files, hide also hides
+	// them from directory listings (default: default)
+	// +optional
+	// +kubebuilder:validation:Enum=default;hide
+	DenyPolicy string `json:"denyPolicy,omitempty"`
+}
+
 // Quota defines storage quota
 type Quota struct {
 	// Total size allowed (in bytes)
@@ -165,7 +298,7 @@ type BandwidthLimits struct {
 
 // RateLimits defines rate limits
 type RateLimits struct {
-	// Average rate (bytes/sec)
+	// Average rate (bytes/sec), rounded up to whole KB/s for SFTPGO
 	// +optional
 	Average int64 `json:"average,omitempty"`
 
@@ -180,15 +313,16 @@ type UserFilters struct {
 	// +optional
 	RequirePasswordChange bool `json:"requirePasswordChange,omitempty"`
 
-	// Require TOTP
+	// Require TOTP for the allowed protocols supporting it (SFTP, FTP, HTTP)
 	// +optional
 	RequireTOTP bool `json:"requireTOTP,omitempty"`
 
-	// External auth hook
+	// External auth hook: only "disabled" is supported, skipping the
+	// server's hook for this user
 	// +optional
 	ExternalAuthHook string `json:"externalAuthHook,omitempty"`
 
-	// Command restrictions
+	// Command restrictions. Not supported by SFTPGO per user.
 	// +optional
 	AllowedCommands []string `json:"allowedCommands,omitempty"`
 
@@ -198,20 +332,55 @@ type UserFilters struct {
 }
 
 // TimeInterval defines a time interval for access
+// +kubebuilder:validation:XValidation:rule="!has(self.start) || !has(self.end) || self.start != self.end",message="start and end must differ, start after end spans midnight"
 type TimeInterval struct {
-	// Start hour (0-23)
+	// Start hour (0-23). Start after end spans midnight.
 	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=23
 	Start int `json:"start,omitempty"`
 
 	// End hour (0-23)
 	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=23
 	End int `json:"end,omitempty"`
 
-	// Days of week (0=Sunday, 1=Monday, etc.)
+	// Days of week (0=Sunday, 1=Monday, etc.), all when empty
 	// +optional
+	// +kubebuilder:validation:items:Minimum=0
+	// +kubebuilder:validation:items:Maximum=6
 	Days []int `json:"days,omitempty"`
 }
 
+// HomeDirPolicy defines the home directory lifecycle, applied by Jobs
+// mounting the server data volume
+type HomeDirPolicy struct {
+	// OnDelete is what happens to the home directory when the user is
+	// deleted: Keep (default), Delete, or Archive into archivePath
+	// +optional
+	// +kubebuilder:validation:Enum=Keep;Delete;Archive
+	OnDelete string `json:"onDelete,omitempty"`
+
+	// ArchivePath is the directory of the server data volume the home
+	// directory is archived into, as <username>-<deletion time>.tar.gz.
+	// Required with onDelete Archive.
+	// +optional
+	ArchivePath string `json:"archivePath,omitempty"`
+
+	// Template is a directory of the server data volume copied into the
+	// home directory before the user is created, unless the home directory
+	// already has files
+	// +optional
+	Template string `json:"template,omitempty"`
+
+	// MigrateOnMove copies the home directory from the data volume of the
+	// previous server when serverRef changes. Both servers must be in the
+	// same namespace, with data volumes a single pod can mount.
+	// +optional
+	MigrateOnMove bool `json:"migrateOnMove,omitempty"`
+}
+
 // FilesystemConfig defines filesystem settings
 type FilesystemConfig struct {
 	// Provider type: osfs, s3fs, gcsfs, azureblob, crypt, encrypted, sftpfs
@@ -285,7 +454,12 @@ type GCSFilesystemConfig struct {
 	// +optional
 	Bucket string `json:"bucket,omitempty"`
 
-	// Credentials file path
+	// Service account JSON key reference. When unset, the SFTPGO pod's
+	// default credentials (e.g. workload identity) are used.
+	// +optional
+	Credentials *SecretRef `json:"credentials,omitempty"`
+
+	// Deprecated: credentials files are not readable by the operator, use Credentials.
 	// +optional
 	CredentialsFile string `json:"credentialsFile,omitempty"`
 
@@ -299,6 +473,7 @@ type GCSFilesystemConfig struct {
 }
 
 // AzureFilesystemConfig defines Azure Blob filesystem settings
+// +kubebuilder:validation:XValidation:rule="!has(self.sasURL) || !has(self.sasURLSecretRef)",message="sasURL and sasURLSecretRef are mutually exclusive"
 type AzureFilesystemConfig struct {
 	// Container name
 	// +optional
@@ -312,10 +487,14 @@ type AzureFilesystemConfig struct {
 	// +optional
 	AccountKey *SecretRef `json:"accountKey,omitempty"`
 
-	// SAS URL
+	// SAS URL, stored in clear text in the resource. Prefer sasURLSecretRef.
 	// +optional
 	SASURL string `json:"sasURL,omitempty"`
 
+	// SAS URL reference, used when sasURL is empty
+	// +optional
+	SASURLSecretRef *SecretRef `json:"sasURLSecretRef,omitempty"`
+
 	// Endpoint suffix
 	// +optional
 	EndpointSuffix string `json:"endpointSuffix,omitempty"`
@@ -362,7 +541,7 @@ type CryptFilesystemConfig struct {
 	// +optional
 	Passphrase *SecretRef `json:"passphrase,omitempty"`
 
-	// Physical path (to encrypt)
+	// Physical path (to encrypt), overrides homeDir
 	// +optional
 	PhysicalPath string `json:"physicalPath,omitempty"`
 }
@@ -388,6 +567,24 @@ type SftpGoUserStatus struct {
 	// +optional
 	UserID int `json:"userID,omitempty"`
 
+	// Username is the SFTPGO username the user was created or adopted with
+	// +optional
+	Username string `json:"username,omitempty"`
+
+	// Server is the SftpGoServer (namespace/name) the user was synced to
+	// +optional
+	Server string `json:"server,omitempty"`
+
+	// MovingFrom is the SftpGoServer (namespace/name) the user is being
+	// moved from, until it is deleted there
+	// +optional
+	MovingFrom string `json:"movingFrom,omitempty"`
+
+	// MovingFromUserID is the SFTPGO user ID on the server the user is being
+	// moved from
+	// +optional
+	MovingFromUserID int `json:"movingFromUserID,omitempty"`
+
 	// Conditions is the list of conditions
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
@@ -395,6 +592,89 @@ type SftpGoUserStatus struct {
 	// LastSynced is the last time the user was synced
 	// +optional
 	LastSynced *metav1.Time `json:"lastSynced,omitempty"`
+
+	// ObservedGeneration is the generation last synced to SFTPGO
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ExpiresAt is when the account expires, from spec.expiresAt or spec.ttl
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// CredentialsGeneratedAt is when the generated credentials were last
+	// (re)generated
+	// +optional
+	CredentialsGeneratedAt *metav1.Time `json:"credentialsGeneratedAt,omitempty"`
+
+	// NextCredentialsRotation is when the generated credentials are rotated next
+	// +optional
+	NextCredentialsRotation *metav1.Time `json:"nextCredentialsRotation,omitempty"`
+
+	// PreviousPublicKeyExpiresAt is when the public key replaced by the last
+	// rotation stops being accepted
+	// +optional
+	PreviousPublicKeyExpiresAt *metav1.Time `json:"previousPublicKeyExpiresAt,omitempty"`
+
+	// SecretsHash is an HMAC of the password and filesystem secrets last sent
+	// to SFTPGO, which only returns them encrypted. Its key is kept in a
+	// Secret of the operator namespace.
+	// +optional
+	SecretsHash string `json:"secretsHash,omitempty"`
+
+	// HomeDirProvisioned is set once the home directory was seeded from
+	// homeDirPolicy.template
+	// +optional
+	HomeDirProvisioned bool `json:"homeDirProvisioned,omitempty"`
+
+	// HomeDirProvisionFailures counts the failed home directory provisioning
+	// Jobs, provisioning waits for a spec change after 3 failures
+	// +optional
+	HomeDirProvisionFailures int32 `json:"homeDirProvisionFailures,omitempty"`
+
+	// HomeDirCleanupFailures counts the failed home directory cleanup Jobs
+	// of the deleted user, the home directory is kept after 3 failures
+	// +optional
+	HomeDirCleanupFailures int32 `json:"homeDirCleanupFailures,omitempty"`
+
+	// Usage is the usage last polled from SFTPGO
+	// +optional
+	Usage *UserUsage `json:"usage,omitempty"`
+}
+
+// UserUsage is the live usage of a user as reported by SFTPGO
+type UserUsage struct {
+	// UsedQuotaSize is the storage used (in bytes)
+	// +optional
+	UsedQuotaSize int64 `json:"usedQuotaSize,omitempty"`
+
+	// UsedQuotaFiles is the number of files stored
+	// +optional
+	UsedQuotaFiles int `json:"usedQuotaFiles,omitempty"`
+
+	// QuotaPercent is the highest of the size and files quota usage, unset
+	// without quota
+	// +optional
+	QuotaPercent *int32 `json:"quotaPercent,omitempty"`
+
+	// LastLogin is the last successful login
+	// +optional
+	LastLogin *metav1.Time `json:"lastLogin,omitempty"`
+
+	// FirstUpload is the first file upload
+	// +optional
+	FirstUpload *metav1.Time `json:"firstUpload,omitempty"`
+
+	// FirstDownload is the first file download
+	// +optional
+	FirstDownload *metav1.Time `json:"firstDownload,omitempty"`
+
+	// ActiveSessions is the number of open connections
+	// +optional
+	ActiveSessions int `json:"activeSessions,omitempty"`
+
+	// PolledAt is when the usage was polled
+	// +optional
+	PolledAt *metav1.Time `json:"polledAt,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -403,6 +683,9 @@ type SftpGoUserStatus struct {
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".spec.status"
 // +kubebuilder:printcolumn:name="HomeDir",type="string",JSONPath=".spec.homeDir"
 // +kubebuilder:printcolumn:name="Server",type="string",JSONPath=".spec.serverRef.name"
+// +kubebuilder:printcolumn:name="Expires",type="date",JSONPath=".status.expiresAt"
+// +kubebuilder:printcolumn:name="Quota%",type="integer",JSONPath=".status.usage.quotaPercent"
+// +kubebuilder:printcolumn:name="Last Login",type="date",JSONPath=".status.usage.lastLogin"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // SftpGoUser is the Schema for the sftpgousers API
@@ -26,6 +26,61 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditLogConfig) DeepCopyInto(out *AuditLogConfig) {
+	*out = *in
+	if in.Transfers != nil {
+		in, out := &in.Transfers, &out.Transfers
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Commands != nil {
+		in, out := &in.Commands, &out.Commands
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditLogConfig.
+func (in *AuditLogConfig) DeepCopy() *AuditLogConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditLogConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthConfig) DeepCopyInto(out *AuthConfig) {
+	*out = *in
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(OIDCConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalAuthHook != nil {
+		in, out := &in.ExternalAuthHook, &out.ExternalAuthHook
+		*out = new(ExternalAuthHookConfig)
+		**out = **in
+	}
+	if in.LDAP != nil {
+		in, out := &in.LDAP, &out.LDAP
+		*out = new(LDAPAuthConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthConfig.
+func (in *AuthConfig) DeepCopy() *AuthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureFilesystemConfig) DeepCopyInto(out *AzureFilesystemConfig) {
 	*out = *in
@@ -34,6 +89,11 @@ func (in *AzureFilesystemConfig) DeepCopyInto(out *AzureFilesystemConfig) {
 		*out = new(SecretRef)
 		**out = **in
 	}
+	if in.SASURLSecretRef != nil {
+		in, out := &in.SASURLSecretRef, &out.SASURLSecretRef
+		*out = new(SecretRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureFilesystemConfig.
@@ -61,6 +121,25 @@ func (in *BandwidthLimits) DeepCopy() *BandwidthLimits {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BannedHost) DeepCopyInto(out *BannedHost) {
+	*out = *in
+	if in.BanUntil != nil {
+		in, out := &in.BanUntil, &out.BanUntil
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BannedHost.
+func (in *BannedHost) DeepCopy() *BannedHost {
+	if in == nil {
+		return nil
+	}
+	out := new(BannedHost)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CommonConfig) DeepCopyInto(out *CommonConfig) {
 	*out = *in
@@ -76,6 +155,23 @@ func (in *CommonConfig) DeepCopy() *CommonConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialRotation) DeepCopyInto(out *CredentialRotation) {
+	*out = *in
+	out.Interval = in.Interval
+	out.Overlap = in.Overlap
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialRotation.
+func (in *CredentialRotation) DeepCopy() *CredentialRotation {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialRotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CryptFilesystemConfig) DeepCopyInto(out *CryptFilesystemConfig) {
 	*out = *in
@@ -116,6 +212,102 @@ func (in *DatabaseConfig) DeepCopy() *DatabaseConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefenderConfig) DeepCopyInto(out *DefenderConfig) {
+	*out = *in
+	if in.Safelist != nil {
+		in, out := &in.Safelist, &out.Safelist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Blocklist != nil {
+		in, out := &in.Blocklist, &out.Blocklist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefenderConfig.
+func (in *DefenderConfig) DeepCopy() *DefenderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DefenderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefenderStatus) DeepCopyInto(out *DefenderStatus) {
+	*out = *in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]BannedHost, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastChecked != nil {
+		in, out := &in.LastChecked, &out.LastChecked
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefenderStatus.
+func (in *DefenderStatus) DeepCopy() *DefenderStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DefenderStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DirectoryPermissions) DeepCopyInto(out *DirectoryPermissions) {
+	*out = *in
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedPatterns != nil {
+		in, out := &in.AllowedPatterns, &out.AllowedPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedPatterns != nil {
+		in, out := &in.DeniedPatterns, &out.DeniedPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectoryPermissions.
+func (in *DirectoryPermissions) DeepCopy() *DirectoryPermissions {
+	if in == nil {
+		return nil
+	}
+	out := new(DirectoryPermissions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalAuthHookConfig) DeepCopyInto(out *ExternalAuthHookConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalAuthHookConfig.
+func (in *ExternalAuthHookConfig) DeepCopy() *ExternalAuthHookConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalAuthHookConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FTPConfig) DeepCopyInto(out *FTPConfig) {
 	*out = *in
@@ -152,7 +344,7 @@ func (in *FilesystemConfig) DeepCopyInto(out *FilesystemConfig) {
 	if in.GCS != nil {
 		in, out := &in.GCS, &out.GCS
 		*out = new(GCSFilesystemConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Azure != nil {
 		in, out := &in.Azure, &out.Azure
@@ -184,6 +376,11 @@ func (in *FilesystemConfig) DeepCopy() *FilesystemConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GCSFilesystemConfig) DeepCopyInto(out *GCSFilesystemConfig) {
 	*out = *in
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = new(SecretRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCSFilesystemConfig.
@@ -196,6 +393,31 @@ func (in *GCSFilesystemConfig) DeepCopy() *GCSFilesystemConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GeneratedCredentials) DeepCopyInto(out *GeneratedCredentials) {
+	*out = *in
+	if in.Password != nil {
+		in, out := &in.Password, &out.Password
+		*out = new(PasswordPolicy)
+		**out = **in
+	}
+	if in.Rotation != nil {
+		in, out := &in.Rotation, &out.Rotation
+		*out = new(CredentialRotation)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeneratedCredentials.
+func (in *GeneratedCredentials) DeepCopy() *GeneratedCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(GeneratedCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPConfig) DeepCopyInto(out *HTTPConfig) {
 	*out = *in
@@ -211,6 +433,261 @@ func (in *HTTPConfig) DeepCopy() *HTTPConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HomeDirPolicy) DeepCopyInto(out *HomeDirPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HomeDirPolicy.
+func (in *HomeDirPolicy) DeepCopy() *HomeDirPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HomeDirPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressConfig) DeepCopyInto(out *IngressConfig) {
+	*out = *in
+	if in.ClassName != nil {
+		in, out := &in.ClassName, &out.ClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressConfig.
+func (in *IngressConfig) DeepCopy() *IngressConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPAuthConfig) DeepCopyInto(out *LDAPAuthConfig) {
+	*out = *in
+	if in.URLs != nil {
+		in, out := &in.URLs, &out.URLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BindPasswordRef != nil {
+		in, out := &in.BindPasswordRef, &out.BindPasswordRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.GroupAttributes != nil {
+		in, out := &in.GroupAttributes, &out.GroupAttributes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPAuthConfig.
+func (in *LDAPAuthConfig) DeepCopy() *LDAPAuthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPAuthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogShipperConfig) DeepCopyInto(out *LogShipperConfig) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogShipperConfig.
+func (in *LogShipperConfig) DeepCopy() *LogShipperConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LogShipperConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingConfig) DeepCopyInto(out *LoggingConfig) {
+	*out = *in
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(AuditLogConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Shipper != nil {
+		in, out := &in.Shipper, &out.Shipper
+		*out = new(LogShipperConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingConfig.
+func (in *LoggingConfig) DeepCopy() *LoggingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCConfig) DeepCopyInto(out *OIDCConfig) {
+	*out = *in
+	out.ClientSecretRef = in.ClientSecretRef
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCConfig.
+func (in *OIDCConfig) DeepCopy() *OIDCConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordPolicy) DeepCopyInto(out *PasswordPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordPolicy.
+func (in *PasswordPolicy) DeepCopy() *PasswordPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginAuthOptions) DeepCopyInto(out *PluginAuthOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginAuthOptions.
+func (in *PluginAuthOptions) DeepCopy() *PluginAuthOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginAuthOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginConfig) DeepCopyInto(out *PluginConfig) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Notifier != nil {
+		in, out := &in.Notifier, &out.Notifier
+		*out = new(PluginNotifierOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KMS != nil {
+		in, out := &in.KMS, &out.KMS
+		*out = new(PluginKMSOptions)
+		**out = **in
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(PluginAuthOptions)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginConfig.
+func (in *PluginConfig) DeepCopy() *PluginConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginKMSOptions) DeepCopyInto(out *PluginKMSOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginKMSOptions.
+func (in *PluginKMSOptions) DeepCopy() *PluginKMSOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginKMSOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginNotifierOptions) DeepCopyInto(out *PluginNotifierOptions) {
+	*out = *in
+	if in.FSEvents != nil {
+		in, out := &in.FSEvents, &out.FSEvents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProviderEvents != nil {
+		in, out := &in.ProviderEvents, &out.ProviderEvents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProviderObjects != nil {
+		in, out := &in.ProviderObjects, &out.ProviderObjects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginNotifierOptions.
+func (in *PluginNotifierOptions) DeepCopy() *PluginNotifierOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginNotifierOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PortRange) DeepCopyInto(out *PortRange) {
 	*out = *in
@@ -226,6 +703,31 @@ func (in *PortRange) DeepCopy() *PortRange {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyProtocolConfig) DeepCopyInto(out *ProxyProtocolConfig) {
+	*out = *in
+	if in.Allowed != nil {
+		in, out := &in.Allowed, &out.Allowed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Skipped != nil {
+		in, out := &in.Skipped, &out.Skipped
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyProtocolConfig.
+func (in *ProxyProtocolConfig) DeepCopy() *ProxyProtocolConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxyProtocolConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Quota) DeepCopyInto(out *Quota) {
 	*out = *in
@@ -241,6 +743,26 @@ func (in *Quota) DeepCopy() *Quota {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimiterConfig) DeepCopyInto(out *RateLimiterConfig) {
+	*out = *in
+	if in.Protocols != nil {
+		in, out := &in.Protocols, &out.Protocols
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimiterConfig.
+func (in *RateLimiterConfig) DeepCopy() *RateLimiterConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimiterConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RateLimits) DeepCopyInto(out *RateLimits) {
 	*out = *in
@@ -354,6 +876,18 @@ func (in *SFTPGOConfig) DeepCopyInto(out *SFTPGOConfig) {
 		*out = new(HTTPConfig)
 		**out = **in
 	}
+	if in.Defender != nil {
+		in, out := &in.Defender, &out.Defender
+		*out = new(DefenderConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RateLimiters != nil {
+		in, out := &in.RateLimiters, &out.RateLimiters
+		*out = make([]RateLimiterConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SFTPGOConfig.
@@ -396,6 +930,33 @@ func (in *ServerRef) DeepCopy() *ServerRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceConfig) DeepCopyInto(out *ServiceConfig) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceConfig.
+func (in *ServiceConfig) DeepCopy() *ServiceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServicePorts) DeepCopyInto(out *ServicePorts) {
 	*out = *in
@@ -479,6 +1040,21 @@ func (in *SftpGoServerSpec) DeepCopyInto(out *SftpGoServerSpec) {
 		**out = **in
 	}
 	in.Config.DeepCopyInto(&out.Config)
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(ServiceConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(IngressConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProxyProtocol != nil {
+		in, out := &in.ProxyProtocol, &out.ProxyProtocol
+		*out = new(ProxyProtocolConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.DataVolume != nil {
 		in, out := &in.DataVolume, &out.DataVolume
 		*out = new(VolumeConfig)
@@ -518,6 +1094,33 @@ func (in *SftpGoServerSpec) DeepCopyInto(out *SftpGoServerSpec) {
 		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
+	if in.AllowedUserNamespaces != nil {
+		in, out := &in.AllowedUserNamespaces, &out.AllowedUserNamespaces
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UserPruning != nil {
+		in, out := &in.UserPruning, &out.UserPruning
+		*out = new(UserPruningConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]PluginConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(AuthConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(LoggingConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SftpGoServerSpec.
@@ -541,6 +1144,16 @@ func (in *SftpGoServerStatus) DeepCopyInto(out *SftpGoServerStatus) {
 		}
 	}
 	out.Ports = in.Ports
+	if in.Defender != nil {
+		in, out := &in.Defender, &out.Defender
+		*out = new(DefenderStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UnmanagedUsers != nil {
+		in, out := &in.UnmanagedUsers, &out.UnmanagedUsers
+		*out = new(UnmanagedUsersStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SftpGoServerStatus.
@@ -620,6 +1233,11 @@ func (in *SftpGoUserSpec) DeepCopyInto(out *SftpGoUserSpec) {
 		*out = new(SecretRef)
 		**out = **in
 	}
+	if in.PasswordHashSecretRef != nil {
+		in, out := &in.PasswordHashSecretRef, &out.PasswordHashSecretRef
+		*out = new(SecretRef)
+		**out = **in
+	}
 	if in.PublicKeys != nil {
 		in, out := &in.PublicKeys, &out.PublicKeys
 		*out = make([]string, len(*in))
@@ -630,6 +1248,11 @@ func (in *SftpGoUserSpec) DeepCopyInto(out *SftpGoUserSpec) {
 		*out = new(SecretRef)
 		**out = **in
 	}
+	if in.GeneratedCredentials != nil {
+		in, out := &in.GeneratedCredentials, &out.GeneratedCredentials
+		*out = new(GeneratedCredentials)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.VirtualFolders != nil {
 		in, out := &in.VirtualFolders, &out.VirtualFolders
 		*out = make([]VirtualFolder, len(*in))
@@ -640,6 +1263,13 @@ func (in *SftpGoUserSpec) DeepCopyInto(out *SftpGoUserSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.DirectoryPermissions != nil {
+		in, out := &in.DirectoryPermissions, &out.DirectoryPermissions
+		*out = make([]DirectoryPermissions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Quota != nil {
 		in, out := &in.Quota, &out.Quota
 		*out = new(Quota)
@@ -681,6 +1311,25 @@ func (in *SftpGoUserSpec) DeepCopyInto(out *SftpGoUserSpec) {
 		*out = new(FilesystemConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.HomeDirPolicy != nil {
+		in, out := &in.HomeDirPolicy, &out.HomeDirPolicy
+		*out = new(HomeDirPolicy)
+		**out = **in
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.DeleteAfterExpiry != nil {
+		in, out := &in.DeleteAfterExpiry, &out.DeleteAfterExpiry
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	out.ServerRef = in.ServerRef
 }
 
@@ -708,6 +1357,27 @@ func (in *SftpGoUserStatus) DeepCopyInto(out *SftpGoUserStatus) {
 		in, out := &in.LastSynced, &out.LastSynced
 		*out = (*in).DeepCopy()
 	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CredentialsGeneratedAt != nil {
+		in, out := &in.CredentialsGeneratedAt, &out.CredentialsGeneratedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.NextCredentialsRotation != nil {
+		in, out := &in.NextCredentialsRotation, &out.NextCredentialsRotation
+		*out = (*in).DeepCopy()
+	}
+	if in.PreviousPublicKeyExpiresAt != nil {
+		in, out := &in.PreviousPublicKeyExpiresAt, &out.PreviousPublicKeyExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(UserUsage)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SftpGoUserStatus.
@@ -740,6 +1410,30 @@ func (in *TimeInterval) DeepCopy() *TimeInterval {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnmanagedUsersStatus) DeepCopyInto(out *UnmanagedUsersStatus) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastChecked != nil {
+		in, out := &in.LastChecked, &out.LastChecked
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnmanagedUsersStatus.
+func (in *UnmanagedUsersStatus) DeepCopy() *UnmanagedUsersStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UnmanagedUsersStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserFilters) DeepCopyInto(out *UserFilters) {
 	*out = *in
@@ -767,6 +1461,67 @@ func (in *UserFilters) DeepCopy() *UserFilters {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserPruningConfig) DeepCopyInto(out *UserPruningConfig) {
+	*out = *in
+	if in.ProtectedUsers != nil {
+		in, out := &in.ProtectedUsers, &out.ProtectedUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserPruningConfig.
+func (in *UserPruningConfig) DeepCopy() *UserPruningConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(UserPruningConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserUsage) DeepCopyInto(out *UserUsage) {
+	*out = *in
+	if in.QuotaPercent != nil {
+		in, out := &in.QuotaPercent, &out.QuotaPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LastLogin != nil {
+		in, out := &in.LastLogin, &out.LastLogin
+		*out = (*in).DeepCopy()
+	}
+	if in.FirstUpload != nil {
+		in, out := &in.FirstUpload, &out.FirstUpload
+		*out = (*in).DeepCopy()
+	}
+	if in.FirstDownload != nil {
+		in, out := &in.FirstDownload, &out.FirstDownload
+		*out = (*in).DeepCopy()
+	}
+	if in.PolledAt != nil {
+		in, out := &in.PolledAt, &out.PolledAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserUsage.
+func (in *UserUsage) DeepCopy() *UserUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(UserUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualFolder) DeepCopyInto(out *VirtualFolder) {
 	*out = *in
@@ -0,0 +1,134 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/sftpgo/sftpgo-operator/internal/controller"
+)
+
+// runImport implements the import command: it generates SftpGoUsers for the
+// SFTPGO users of a server, printed as YAML or created in the cluster
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	var kubeconfig, server, serverNamespace string
+	var opts controller.ImportOptions
+	var apply bool
+	fs.StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig, the in-cluster or default config when empty.")
+	fs.StringVar(&server, "server", "", "Name of the SftpGoServer whose users are imported.")
+	fs.StringVar(&serverNamespace, "server-namespace", "default", "Namespace of the SftpGoServer.")
+	fs.StringVar(&opts.Namespace, "namespace", "", "Namespace of the SftpGoUsers, the server namespace when empty.")
+	fs.StringVar(&opts.URL, "url", "",
+		"URL of the SFTPGO API, the in-cluster server Service when empty (e.g. a kubectl port-forward).")
+	fs.StringVar(&opts.Adoption, "adoption", "adopt",
+		"Adoption annotation of the SftpGoUsers: adopt, ignore or fail-if-exists. "+
+			"Users with settings that cannot be imported get fail-if-exists instead of adopt.")
+	fs.BoolVar(&apply, "apply", false, "Create the SftpGoUsers in the cluster instead of printing them.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if server == "" {
+		return fmt.Errorf("--server is required")
+	}
+	opts.Server = types.NamespacedName{Name: server, Namespace: serverNamespace}
+
+	var cfg *rest.Config
+	var err error
+	if kubeconfig != "" {
+		cfg, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	} else {
+		cfg, err = ctrl.GetConfig()
+	}
+	if err != nil {
+		return err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	imported, err := controller.ImportUsers(ctx, c, opts)
+	if err != nil {
+		return err
+	}
+	for _, iu := range imported {
+		comments := make([]string, 0, len(iu.Skipped)+1)
+		for _, s := range iu.Skipped {
+			comments = append(comments, "not imported: "+s)
+		}
+		if iu.AdoptionDowngraded {
+			comments = append(comments, "adoption is fail-if-exists: set the settings not imported, then annotate with adopt")
+		}
+		for _, c := range comments {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", iu.User.Spec.Username, c)
+		}
+		if !apply {
+			if err := writeManifest(os.Stdout, iu.User, comments); err != nil {
+				return err
+			}
+			continue
+		}
+		switch err := c.Create(ctx, iu.User); {
+		case errors.IsAlreadyExists(err):
+			fmt.Fprintf(os.Stderr, "%s: skipped, SftpGoUser %s/%s exists\n", iu.User.Spec.Username, iu.User.Namespace, iu.User.Name)
+		case err != nil:
+			return err
+		default:
+			fmt.Fprintf(os.Stderr, "%s: created SftpGoUser %s/%s\n", iu.User.Spec.Username, iu.User.Namespace, iu.User.Name)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d users to import\n", len(imported))
+	return nil
+}
+
+// writeManifest writes an object as a YAML document, without status and
+// server-set metadata, preceded by comments
+func writeManifest(w io.Writer, obj runtime.Object, comments []string) error {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	delete(u, "status")
+	if md, ok := u["metadata"].(map[string]any); ok {
+		delete(md, "creationTimestamp")
+	}
+	out, err := yaml.Marshal(u)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "---")
+	for _, c := range comments {
+		fmt.Fprintf(w, "# %s\n", c)
+	}
+	_, err = w.Write(out)
+	return err
+}
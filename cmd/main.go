@@ -17,20 +17,26 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -39,6 +45,7 @@ import (
 
 	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
 	"github.com/sftpgo/sftpgo-operator/internal/controller"
+	webhooksftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -56,6 +63,14 @@ func init() {
 
 // nolint:gocyclo
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
@@ -63,6 +78,11 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var userResyncInterval time.Duration
+	var userUsageInterval time.Duration
+	var inlineSecrets string
+	var homeDirJobImage string
+	var secretsHashKeySecret string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -81,6 +101,19 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.DurationVar(&userResyncInterval, "user-resync-interval", 10*time.Minute,
+		"How often SftpGoUsers are compared with SFTPGO and drifted fields reverted. 0 disables the resync.")
+	flag.DurationVar(&userUsageInterval, "user-usage-interval", 5*time.Minute,
+		"How often the usage of SftpGoUsers is polled from SFTPGO into their status. 0 disables the polling.")
+	flag.StringVar(&inlineSecrets, "inline-secrets", string(controller.InlineSecretsAllow),
+		"How secrets set in clear text in SftpGoUser specs, such as spec.password, are handled: "+
+			"allow, reject, or convert to move them into a Secret owned by the user.")
+	flag.StringVar(&homeDirJobImage, "home-dir-job-image", controller.DefaultHomeDirJobImage,
+		"The image of the Jobs seeding, deleting, archiving and migrating home directories. "+
+			"It needs a shell with cp, tar and rm, which the SFTPGO images do not have.")
+	flag.StringVar(&secretsHashKeySecret, "secrets-hash-key-secret", controller.DefaultSecretsHashKeySecret,
+		"The Secret of the operator namespace holding the key of the secrets hashes in SftpGoUser status, "+
+			"created with a random key when missing.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -89,6 +122,14 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	inlineSecretsPolicy := controller.InlineSecretsPolicy(inlineSecrets)
+	switch inlineSecretsPolicy {
+	case controller.InlineSecretsAllow, controller.InlineSecretsReject, controller.InlineSecretsConvert:
+	default:
+		setupLog.Error(fmt.Errorf("unknown policy %q", inlineSecrets), "invalid --inline-secrets")
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -202,20 +243,52 @@ func main() {
 		os.Exit(1)
 	}
 
+	// The manager cache is not started yet
+	setupClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		os.Exit(1)
+	}
+	secretsHashKey, err := controller.LoadSecretsHashKey(context.Background(), setupClient,
+		types.NamespacedName{Name: secretsHashKeySecret, Namespace: operatorNamespace()})
+	if err != nil {
+		setupLog.Error(err, "unable to load the secrets hash key")
+		os.Exit(1)
+	}
+
 	if err := (&controller.SftpGoServerReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("sftpgoserver-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SftpGoServer")
 		os.Exit(1)
 	}
 	if err := (&controller.SftpGoUserReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Recorder:        mgr.GetEventRecorderFor("sftpgouser-controller"),
+		ResyncInterval:  userResyncInterval,
+		UsageInterval:   userUsageInterval,
+		InlineSecrets:   inlineSecretsPolicy,
+		HomeDirJobImage: homeDirJobImage,
+		SecretsHashKey:  secretsHashKey,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SftpGoUser")
 		os.Exit(1)
 	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err := webhooksftpgov1alpha1.SetupSftpGoServerWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SftpGoServer")
+			os.Exit(1)
+		}
+		if err := webhooksftpgov1alpha1.SetupSftpGoUserWebhookWithManager(mgr,
+			inlineSecretsPolicy == controller.InlineSecretsReject); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SftpGoUser")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -249,3 +322,15 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// operatorNamespace returns the namespace the operator runs in, from the
+// POD_NAMESPACE variable or the service account, default outside a cluster
+func operatorNamespace() string {
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		return ns
+	}
+	if ns, err := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace"); err == nil {
+		return strings.TrimSpace(string(ns))
+	}
+	return "default"
+}
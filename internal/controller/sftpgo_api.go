@@ -0,0 +1,60 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+	"github.com/sftpgo/sftpgo-operator/internal/sftpgo"
+)
+
+// serverAPIURL returns the REST API URL of a server (service is same name as server)
+func serverAPIURL(server *sftpgov1alpha1.SftpGoServer) string {
+	webPort := sftpgov1alpha1.DefaultWebPort
+	if server.Spec.WebPort > 0 {
+		webPort = server.Spec.WebPort
+	}
+	if server.Spec.Config.HTTP != nil && server.Spec.Config.HTTP.Port > 0 {
+		webPort = server.Spec.Config.HTTP.Port
+	}
+	return sftpgo.ServiceURL(server.Name, server.Namespace, webPort)
+}
+
+// adminCredentials reads the admin username and password from the server's
+// AdminSecretRef. Empty values are returned when no secret is configured.
+func adminCredentials(ctx context.Context, c client.Reader, server *sftpgov1alpha1.SftpGoServer) (string, string, error) {
+	if server.Spec.AdminSecretRef == nil || server.Spec.AdminSecretRef.Name == "" {
+		return "", "", nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{
+		Name:      server.Spec.AdminSecretRef.Name,
+		Namespace: server.Namespace,
+	}, secret); err != nil {
+		return "", "", err
+	}
+
+	username := string(secret.Data["username"])
+	password := string(secret.Data["password"])
+	return username, password, nil
+}
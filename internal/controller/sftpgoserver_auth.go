@@ -0,0 +1,190 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+const (
+	ldapAuthPluginBinary = "/usr/local/bin/sftpgo-plugin-auth"
+	ldapAuthDefaultScope = 5 // password + keyboard interactive
+
+	// oidcClientSecretEnv overrides the client secret of the first httpd binding,
+	// keeping the secret out of the ConfigMap
+	oidcClientSecretEnv = "SFTPGO_HTTPD__BINDINGS__0__OIDC__CLIENT_SECRET"
+)
+
+// oidcJSON is the SFTPGO "httpd.bindings[].oidc" config section
+type oidcJSON struct {
+	ConfigURL       string   `json:"config_url"`
+	ClientID        string   `json:"client_id"`
+	RedirectBaseURL string   `json:"redirect_base_url"`
+	UsernameField   string   `json:"username_field"`
+	RoleField       string   `json:"role_field,omitempty"`
+	ImplicitRoles   bool     `json:"implicit_roles"`
+	Scopes          []string `json:"scopes"`
+}
+
+// oidcRedirectBaseURL returns the explicit redirect base URL or derives it from
+// the ingress host
+func oidcRedirectBaseURL(spec *sftpgov1alpha1.SftpGoServerSpec) string {
+	if spec.Auth.OIDC.RedirectBaseURL != "" {
+		return strings.TrimSuffix(spec.Auth.OIDC.RedirectBaseURL, "/")
+	}
+	if spec.Ingress == nil || spec.Ingress.Host == "" {
+		return ""
+	}
+	if spec.Ingress.TLSSecretName != "" {
+		return "https://" + spec.Ingress.Host
+	}
+	return "http://" + spec.Ingress.Host
+}
+
+// oidcEnabled reports whether OIDC is configured and can be rendered
+func oidcEnabled(spec *sftpgov1alpha1.SftpGoServerSpec) bool {
+	return spec.Auth != nil && spec.Auth.OIDC != nil && oidcRedirectBaseURL(spec) != ""
+}
+
+// oidcBindingConfig renders the "oidc" entry of the httpd binding, or "" if
+// OIDC is not enabled
+func oidcBindingConfig(spec *sftpgov1alpha1.SftpGoServerSpec) string {
+	if !oidcEnabled(spec) {
+		return ""
+	}
+	o := spec.Auth.OIDC
+	cfg := oidcJSON{
+		ConfigURL:       o.ConfigURL,
+		ClientID:        o.ClientID,
+		RedirectBaseURL: oidcRedirectBaseURL(spec),
+		UsernameField:   o.UsernameField,
+		RoleField:       o.RoleField,
+		ImplicitRoles:   o.ImplicitRoles,
+		Scopes:          o.Scopes,
+	}
+	if cfg.UsernameField == "" {
+		cfg.UsernameField = "preferred_username"
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "profile", "email"}
+	}
+	b, _ := json.Marshal(cfg)
+	return `, "oidc": ` + string(b)
+}
+
+// externalAuthConfig renders the external auth entries of the "data_provider"
+// section, or "" if no hook is configured
+func externalAuthConfig(spec *sftpgov1alpha1.SftpGoServerSpec) string {
+	if spec.Auth == nil || spec.Auth.ExternalAuthHook == nil {
+		return ""
+	}
+	hook, _ := json.Marshal(spec.Auth.ExternalAuthHook.Hook)
+	return fmt.Sprintf(`,
+    "external_auth_hook": %s,
+    "external_auth_scope": %d`, hook, spec.Auth.ExternalAuthHook.Scope)
+}
+
+// authEnv returns the env variables carrying the auth secrets
+func authEnv(spec *sftpgov1alpha1.SftpGoServerSpec) []corev1.EnvVar {
+	if !oidcEnabled(spec) {
+		return nil
+	}
+	return []corev1.EnvVar{secretEnv(oidcClientSecretEnv, spec.Auth.OIDC.ClientSecretRef)}
+}
+
+// ldapAuthPlugin returns the auth plugin entry implementing the LDAP settings
+func ldapAuthPlugin(l *sftpgov1alpha1.LDAPAuthConfig) sftpgov1alpha1.PluginConfig {
+	binary := l.Binary
+	if binary == "" {
+		binary = ldapAuthPluginBinary
+	}
+	scope := l.Scope
+	if scope == 0 {
+		scope = ldapAuthDefaultScope
+	}
+	env := []corev1.EnvVar{
+		{Name: "SFTPGO_PLUGIN_AUTH_LDAP_URL", Value: strings.Join(l.URLs, ",")},
+		{Name: "SFTPGO_PLUGIN_AUTH_LDAP_BASE_DN", Value: l.BaseDN},
+	}
+	if l.BindDN != "" {
+		env = append(env, corev1.EnvVar{Name: "SFTPGO_PLUGIN_AUTH_LDAP_USERNAME", Value: l.BindDN})
+	}
+	if l.BindPasswordRef != nil {
+		env = append(env, secretEnv("SFTPGO_PLUGIN_AUTH_LDAP_PASSWORD", *l.BindPasswordRef))
+	}
+	if l.SearchQuery != "" {
+		env = append(env, corev1.EnvVar{Name: "SFTPGO_PLUGIN_AUTH_LDAP_SEARCH_QUERY", Value: l.SearchQuery})
+	}
+	if len(l.GroupAttributes) > 0 {
+		env = append(env, corev1.EnvVar{Name: "SFTPGO_PLUGIN_AUTH_LDAP_GROUP_ATTRIBUTES", Value: strings.Join(l.GroupAttributes, ",")})
+	}
+	if l.UsersBaseDir != "" {
+		env = append(env, corev1.EnvVar{Name: "SFTPGO_PLUGIN_AUTH_USERS_BASE_DIR", Value: l.UsersBaseDir})
+	}
+	if l.StartTLS {
+		env = append(env, corev1.EnvVar{Name: "SFTPGO_PLUGIN_AUTH_STARTTLS", Value: "true"})
+	}
+	if l.SkipTLSVerify {
+		env = append(env, corev1.EnvVar{Name: "SFTPGO_PLUGIN_AUTH_SKIP_TLS_VERIFY", Value: "true"})
+	}
+	return sftpgov1alpha1.PluginConfig{
+		Name:   sftpgov1alpha1.LDAPAuthPluginName,
+		Type:   "auth",
+		Image:  l.Image,
+		Binary: binary,
+		Args:   []string{"serve"},
+		Env:    env,
+		Auth:   &sftpgov1alpha1.PluginAuthOptions{Scope: scope},
+	}
+}
+
+// authCondition reports auth settings that cannot be applied
+func authCondition(spec *sftpgov1alpha1.SftpGoServerSpec) *metav1.Condition {
+	if spec.Auth == nil {
+		return nil
+	}
+	cond := &metav1.Condition{
+		Type:   "AuthConfigured",
+		Status: metav1.ConditionTrue,
+		Reason: "Configured",
+	}
+	if spec.Auth.OIDC != nil && !oidcEnabled(spec) {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "OIDCRedirectURLUnknown"
+		cond.Message = "OIDC requires auth.oidc.redirectBaseURL or an ingress host; OIDC login is disabled"
+	}
+	return cond
+}
+
+func secretEnv(name string, ref sftpgov1alpha1.SecretRef) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: ref.Name},
+				Key:                  ref.Key,
+			},
+		},
+	}
+}
@@ -18,10 +18,15 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"sort"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -29,23 +34,28 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+	"github.com/sftpgo/sftpgo-operator/internal/sftpgo"
 )
 
 const (
 	sftpgoServerFinalizer = "sftpgo.sftpgo.io/finalizer"
 	sftpgoDefaultImage    = "docker.io/drakkan/sftpgo:latest"
+	sftpgoConfigDir       = "/etc/sftpgo"
+	configHashAnnotation  = "sftpgo.sftpgo.io/config-hash"
 )
 
 // SftpGoServerReconciler reconciles a SftpGoServer object
 type SftpGoServerReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=sftpgo.sftpgo.io,resources=sftpgoservers,verbs=get;list;watch;create;update;patch;delete
@@ -56,6 +66,10 @@ type SftpGoServerReconciler struct {
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=sftpgo.sftpgo.io,resources=sftpgousers,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *SftpGoServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
@@ -151,6 +165,17 @@ func (r *SftpGoServerReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
+	// Surface plugin install/load failures from the pods
+	if len(spec.Plugins) > 0 {
+		pods := &corev1.PodList{}
+		if err := r.List(ctx, pods, client.InNamespace(server.Namespace), client.MatchingLabels(labelsForServer(server))); err != nil {
+			return ctrl.Result{}, err
+		}
+		meta.SetStatusCondition(&server.Status.Conditions, pluginsCondition(spec.Plugins, pods.Items))
+	} else {
+		meta.RemoveStatusCondition(&server.Status.Conditions, "PluginsReady")
+	}
+
 	// Create or update Service
 	desiredSvc := r.serviceForServer(server)
 	svc := &corev1.Service{}
@@ -161,6 +186,8 @@ func (r *SftpGoServerReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		svc.Spec.Ports = desiredSvc.Spec.Ports
 		svc.Spec.Selector = desiredSvc.Spec.Selector
 		svc.Spec.Type = desiredSvc.Spec.Type
+		svc.Spec.ExternalTrafficPolicy = desiredSvc.Spec.ExternalTrafficPolicy
+		svc.Spec.LoadBalancerSourceRanges = desiredSvc.Spec.LoadBalancerSourceRanges
 		svc.Annotations = desiredSvc.Annotations
 		return controllerutil.SetControllerReference(server, svc, r.Scheme)
 	}); err != nil {
@@ -168,7 +195,35 @@ func (r *SftpGoServerReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
+	// Create, update or remove Ingress
+	if spec.Ingress != nil {
+		desiredIng := r.ingressForServer(server)
+		ing := &networkingv1.Ingress{}
+		ing.Name = desiredIng.Name
+		ing.Namespace = desiredIng.Namespace
+		if err := r.createOrUpdate(ctx, server, ing, func() error {
+			ing.Labels = desiredIng.Labels
+			ing.Annotations = desiredIng.Annotations
+			ing.Spec = desiredIng.Spec
+			return controllerutil.SetControllerReference(server, ing, r.Scheme)
+		}); err != nil {
+			log.Error(err, "Failed to create/update Ingress")
+			return ctrl.Result{}, err
+		}
+	} else {
+		ing := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: server.Name, Namespace: server.Namespace}}
+		if err := r.Delete(ctx, ing); err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+	}
+
 	// Update status
+	meta.SetStatusCondition(&server.Status.Conditions, proxyProtocolCondition(spec))
+	if cond := authCondition(spec); cond != nil {
+		meta.SetStatusCondition(&server.Status.Conditions, *cond)
+	} else {
+		meta.RemoveStatusCondition(&server.Status.Conditions, "AuthConfigured")
+	}
 	meta.SetStatusCondition(&server.Status.Conditions, metav1.Condition{
 		Type:   "Ready",
 		Status: metav1.ConditionTrue,
@@ -186,27 +241,94 @@ func (r *SftpGoServerReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		server.Status.ReadyReplicas = deployment.Status.ReadyReplicas
 	}
 
+	// The safelist and blocklist live in the SFTPGO data provider
+	if spec.Config.Defender != nil && deployment.Status.ReadyReplicas > 0 {
+		if err := r.syncDefenderList(ctx, server, spec.Config.Defender); err != nil {
+			log.Error(err, "Failed to sync the SFTPGO defender list")
+		}
+	}
+
+	// Summarise banned hosts; polled because SFTPGO does not notify bans
+	result := ctrl.Result{}
+	if spec.Config.Defender != nil && spec.Config.Defender.Enabled {
+		result.RequeueAfter = defenderStatusInterval
+		if deployment.Status.ReadyReplicas > 0 && defenderStatusDue(server.Status.Defender) {
+			if err := r.updateDefenderStatus(ctx, server); err != nil {
+				log.Error(err, "Failed to query SFTPGO defender")
+			}
+		}
+	} else {
+		server.Status.Defender = nil
+	}
+
+	// Report, and delete, the SFTPGO users no SftpGoUser manages
+	if spec.UserManagement == userManagementExclusive {
+		interval := userScanInterval(spec.UserPruning)
+		if result.RequeueAfter == 0 || interval < result.RequeueAfter {
+			result.RequeueAfter = interval
+		}
+		if deployment.Status.ReadyReplicas > 0 && userScanDue(server.Status.UnmanagedUsers, interval) {
+			if err := r.pruneUnmanagedUsers(ctx, server, spec); err != nil {
+				log.Error(err, "Failed to prune unmanaged SFTPGO users")
+			}
+		}
+	} else {
+		server.Status.UnmanagedUsers = nil
+	}
+
 	if err := r.Status().Update(ctx, server); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	return result, nil
+}
+
+func (r *SftpGoServerReconciler) updateDefenderStatus(ctx context.Context, server *sftpgov1alpha1.SftpGoServer) error {
+	username, password, err := adminCredentials(ctx, r.Client, server)
+	if err != nil || username == "" || password == "" {
+		return err
+	}
+	status, err := defenderStatus(sftpgo.NewClient(serverAPIURL(server), username, password))
+	if err != nil {
+		return err
+	}
+	server.Status.Defender = status
+	return nil
+}
+
+func (r *SftpGoServerReconciler) syncDefenderList(ctx context.Context, server *sftpgov1alpha1.SftpGoServer,
+	d *sftpgov1alpha1.DefenderConfig) error {
+	username, password, err := adminCredentials(ctx, r.Client, server)
+	if err != nil || username == "" || password == "" {
+		return err
+	}
+	return syncDefenderList(sftpgo.NewClient(serverAPIURL(server), username, password), d)
 }
 
 func (r *SftpGoServerReconciler) applyDefaults(s *sftpgov1alpha1.SftpGoServer) *sftpgov1alpha1.SftpGoServerSpec {
 	spec := s.Spec.DeepCopy()
+	if spec.Auth != nil && spec.Auth.LDAP != nil {
+		// The LDAP auth plugin ships with the plugins image
+		if spec.Auth.LDAP.Image == "" && spec.ImageVariant == "" {
+			spec.ImageVariant = "plugins"
+		}
+		spec.Plugins = append(spec.Plugins, ldapAuthPlugin(spec.Auth.LDAP))
+	}
 	if spec.Image == "" {
 		spec.Image = sftpgoDefaultImage
+		if img, ok := sftpgoImageVariants[spec.ImageVariant]; ok {
+			spec.Image = img
+		}
 	}
 	if spec.Replicas == nil {
 		one := int32(1)
 		spec.Replicas = &one
 	}
 	if spec.SFTPPort == 0 {
-		spec.SFTPPort = 2022
+		spec.SFTPPort = sftpgov1alpha1.DefaultSFTPPort
 	}
 	if spec.WebPort == 0 {
-		spec.WebPort = 8080
+		spec.WebPort = sftpgov1alpha1.DefaultWebPort
 	}
 	if spec.StorageBackend == "" {
 		spec.StorageBackend = "sqlite"
@@ -248,7 +370,7 @@ func (r *SftpGoServerReconciler) configMapForServer(s *sftpgov1alpha1.SftpGoServ
 	spec := r.applyDefaults(s)
 	config := sftpgoMinimalConfig(spec, spec.AdminSecretRef != nil)
 
-	return &corev1.ConfigMap{
+	cm := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      s.Name,
 			Namespace: s.Namespace,
@@ -257,25 +379,74 @@ func (r *SftpGoServerReconciler) configMapForServer(s *sftpgov1alpha1.SftpGoServ
 			"sftpgo.json": config,
 		},
 	}
+	if logShipper(spec.Logging) != nil {
+		cm.Data[fluentBitConfigFile] = fluentBitConfig(spec.Logging)
+	}
+	return cm
+}
+
+// commonJSON is the SFTPGO "common" config section
+type commonJSON struct {
+	ProxyProtocol int               `json:"proxy_protocol,omitempty"`
+	ProxyAllowed  []string          `json:"proxy_allowed,omitempty"`
+	ProxySkipped  []string          `json:"proxy_skipped,omitempty"`
+	Defender      *defenderJSON     `json:"defender,omitempty"`
+	RateLimiters  []rateLimiterJSON `json:"rate_limiters,omitempty"`
+}
+
+// commonConfig renders the SFTPGO "common" section, or "" if nothing is set
+func commonConfig(spec *sftpgov1alpha1.SftpGoServerSpec) string {
+	common := commonJSON{
+		Defender:     defenderConfig(spec.Config.Defender),
+		RateLimiters: rateLimitersConfig(spec.Config.RateLimiters),
+	}
+	if pp := spec.ProxyProtocol; pp != nil {
+		common.ProxyProtocol = proxyProtocolModes[pp.Mode]
+		common.ProxyAllowed = pp.Allowed
+		common.ProxySkipped = pp.Skipped
+	}
+	b, _ := json.Marshal(common)
+	if string(b) == "{}" {
+		return ""
+	}
+	return string(b)
 }
 
 func sftpgoMinimalConfig(spec *sftpgov1alpha1.SftpGoServerSpec, createDefaultAdmin bool) string {
-	sftpPort := int32(2022)
+	sftpPort := sftpgov1alpha1.DefaultSFTPPort
 	if spec.SFTPPort > 0 {
 		sftpPort = spec.SFTPPort
 	}
 	if spec.Config.SFTP != nil && spec.Config.SFTP.Port > 0 {
 		sftpPort = spec.Config.SFTP.Port
 	}
+	webPort := sftpgov1alpha1.DefaultWebPort
+	if spec.WebPort > 0 {
+		webPort = spec.WebPort
+	}
+	if spec.Config.HTTP != nil && spec.Config.HTTP.Port > 0 {
+		webPort = spec.Config.HTTP.Port
+	}
+	applyProxy := spec.ProxyProtocol != nil && spec.ProxyProtocol.Mode != "off"
 	dataProvider := fmt.Sprintf(`"driver": "%s",
     "name": "/srv/sftpgo/sftpgo.db"`, spec.StorageBackend)
 	if createDefaultAdmin {
 		dataProvider += `,
     "create_default_admin": true`
+	}
+	dataProvider += externalAuthConfig(spec)
+	extra := ""
+	if common := commonConfig(spec); common != "" {
+		extra += `,
+  "common": ` + common
+	}
+	if len(spec.Plugins) > 0 {
+		extra += `,
+  "plugins": ` + pluginsConfig(spec.Plugins)
 	}
 	return fmt.Sprintf(`{
   "sftpd": {
-    "bindings": [{"port": %d, "address": "", "apply_proxy_config": true}],
+    "bindings": [{"port": %d, "address": "", "apply_proxy_config": %t}],
     "max_auth_tries": 0,
     "host_keys": [],
     "keyboard_interactive_authentication": true,
@@ -285,9 +456,9 @@ func sftpgoMinimalConfig(spec *sftpgov1alpha1.SftpGoServerSpec, createDefaultAdm
     %s
   },
   "httpd": {
-    "bindings": [{"port": 8080, "address": "", "enable_web_admin": true, "enable_rest_api": true}]
-  }
-}`, sftpPort, dataProvider)
+    "bindings": [{"port": %d, "address": "", "enable_web_admin": true, "enable_rest_api": true%s}]
+  }%s
+}`, sftpPort, applyProxy, dataProvider, webPort, oidcBindingConfig(spec), extra)
 }
 
 func (r *SftpGoServerReconciler) pvcForServer(s *sftpgov1alpha1.SftpGoServer) *corev1.PersistentVolumeClaim {
@@ -318,10 +489,7 @@ func (r *SftpGoServerReconciler) pvcForServer(s *sftpgov1alpha1.SftpGoServer) *c
 
 func (r *SftpGoServerReconciler) deploymentForServer(s *sftpgov1alpha1.SftpGoServer) *appsv1.Deployment {
 	spec := r.applyDefaults(s)
-	labels := map[string]string{
-		"app":        "sftpgo",
-		"controller": s.Name,
-	}
+	labels := labelsForServer(s)
 	replicas := int32(1)
 	if spec.Replicas != nil {
 		replicas = *spec.Replicas
@@ -339,7 +507,7 @@ func (r *SftpGoServerReconciler) deploymentForServer(s *sftpgov1alpha1.SftpGoSer
 		},
 	}
 	volumeMounts := []corev1.VolumeMount{
-		{Name: "config", MountPath: "/etc/sftpgo", ReadOnly: true},
+		{Name: "config", MountPath: sftpgoConfigDir, ReadOnly: true},
 	}
 
 	if spec.DataVolume != nil {
@@ -365,16 +533,34 @@ func (r *SftpGoServerReconciler) deploymentForServer(s *sftpgov1alpha1.SftpGoSer
 		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "data", MountPath: mountPath})
 	}
 
+	if logFileEnabled(spec.Logging) {
+		volumes = append(volumes, corev1.Volume{
+			Name:         sftpgoLogsVolume,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: sftpgoLogsVolume, MountPath: sftpgoLogsDir})
+	}
+
+	initContainers := pluginInitContainers(spec.Plugins)
+	if len(initContainers) > 0 {
+		volumes = append(volumes, corev1.Volume{
+			Name:         sftpgoPluginsVolume,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: sftpgoPluginsVolume, MountPath: sftpgoPluginsDir, ReadOnly: true})
+	}
+
 	container := corev1.Container{
 		Name:            "sftpgo",
 		Image:           spec.Image,
 		ImagePullPolicy: spec.ImagePullPolicy,
-		Args:            []string{"sftpgo", "serve", "--config-file", "/etc/sftpgo/sftpgo.json"},
+		Args:            append([]string{"sftpgo", "serve", "--config-file", sftpgoConfigDir + "/sftpgo.json"}, loggingArgs(spec.Logging)...),
 		Ports: []corev1.ContainerPort{
 			{Name: "sftp", ContainerPort: r.getSFTPPort(spec), Protocol: corev1.ProtocolTCP},
 			{Name: "web", ContainerPort: r.getWebPort(spec), Protocol: corev1.ProtocolTCP},
 		},
-		VolumeMounts: volumeMounts,
+		VolumeMounts:             volumeMounts,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 	}
 	if spec.AdminSecretRef != nil {
 		secretName := spec.AdminSecretRef.Name
@@ -399,9 +585,15 @@ func (r *SftpGoServerReconciler) deploymentForServer(s *sftpgov1alpha1.SftpGoSer
 			},
 		}
 	}
+	container.Env = append(container.Env, authEnv(spec)...)
+	container.Env = append(container.Env, pluginEnv(spec.Plugins)...)
 	if spec.Resources != nil {
 		container.Resources = *spec.Resources
 	}
+	containers := []corev1.Container{container}
+	if shipper := logShipperContainer(spec.Logging); shipper != nil {
+		containers = append(containers, *shipper)
+	}
 
 	dep := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -412,10 +604,15 @@ func (r *SftpGoServerReconciler) deploymentForServer(s *sftpgov1alpha1.SftpGoSer
 			Replicas: &replicas,
 			Selector: &metav1.LabelSelector{MatchLabels: labels},
 			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+					// Roll the pods when the rendered configuration changes
+					Annotations: map[string]string{configHashAnnotation: configHash(r.configMapForServer(s).Data)},
+				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: spec.ServiceAccount,
-					Containers:         []corev1.Container{container},
+					InitContainers:     initContainers,
+					Containers:         containers,
 					Volumes:            volumes,
 					NodeSelector:       spec.NodeSelector,
 					Tolerations:        spec.Tolerations,
@@ -429,12 +626,9 @@ func (r *SftpGoServerReconciler) deploymentForServer(s *sftpgov1alpha1.SftpGoSer
 
 func (r *SftpGoServerReconciler) serviceForServer(s *sftpgov1alpha1.SftpGoServer) *corev1.Service {
 	spec := r.applyDefaults(s)
-	labels := map[string]string{
-		"app":        "sftpgo",
-		"controller": s.Name,
-	}
+	labels := labelsForServer(s)
 
-	return &corev1.Service{
+	svc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      s.Name,
 			Namespace: s.Namespace,
@@ -447,6 +641,81 @@ func (r *SftpGoServerReconciler) serviceForServer(s *sftpgov1alpha1.SftpGoServer
 			},
 		},
 	}
+	if spec.Service != nil {
+		svc.Annotations = spec.Service.Annotations
+		svc.Spec.Type = spec.Service.Type
+		if spec.Service.Type == corev1.ServiceTypeNodePort || spec.Service.Type == corev1.ServiceTypeLoadBalancer {
+			svc.Spec.ExternalTrafficPolicy = spec.Service.ExternalTrafficPolicy
+		}
+		if spec.Service.Type == corev1.ServiceTypeLoadBalancer {
+			svc.Spec.LoadBalancerSourceRanges = spec.Service.LoadBalancerSourceRanges
+		}
+	}
+	return svc
+}
+
+func (r *SftpGoServerReconciler) ingressForServer(s *sftpgov1alpha1.SftpGoServer) *networkingv1.Ingress {
+	spec := r.applyDefaults(s)
+	pathType := networkingv1.PathTypePrefix
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        s.Name,
+			Namespace:   s.Namespace,
+			Labels:      labelsForServer(s),
+			Annotations: spec.Ingress.Annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: spec.Ingress.ClassName,
+			Rules: []networkingv1.IngressRule{{
+				Host: spec.Ingress.Host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: s.Name,
+									Port: networkingv1.ServiceBackendPort{Name: "web"},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	if spec.Ingress.TLSSecretName != "" {
+		ing.Spec.TLS = []networkingv1.IngressTLS{{
+			Hosts:      []string{spec.Ingress.Host},
+			SecretName: spec.Ingress.TLSSecretName,
+		}}
+	}
+	return ing
+}
+
+// configHash returns a stable hash of the ConfigMap data
+func configHash(data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write([]byte(data[k]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func labelsForServer(s *sftpgov1alpha1.SftpGoServer) map[string]string {
+	return map[string]string{
+		"app":        "sftpgo",
+		"controller": s.Name,
+	}
 }
 
 func resourceQuantity(s string) *resource.Quantity {
@@ -462,6 +731,8 @@ func intStr(i int32) intstr.IntOrString {
 func (r *SftpGoServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&sftpgov1alpha1.SftpGoServer{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&networkingv1.Ingress{}).
 		Named("sftpgoserver").
 		Complete(r)
 }
@@ -18,16 +18,23 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+	"github.com/sftpgo/sftpgo-operator/internal/sftpgo"
 )
 
 var _ = Describe("SftpGoServer Controller", func() {
@@ -81,4 +88,230 @@ var _ = Describe("SftpGoServer Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When plugins are configured", func() {
+		It("should install them with init containers and render the plugins config", func() {
+			server := &sftpgov1alpha1.SftpGoServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "with-plugins", Namespace: "default"},
+				Spec: sftpgov1alpha1.SftpGoServerSpec{
+					Plugins: []sftpgov1alpha1.PluginConfig{
+						{Name: "notify", Type: "notifier", Image: "example.com/notify:v1", Binary: "/bin/notify"},
+						{Name: "auth", Type: "auth", Binary: "/usr/local/bin/sftpgo-plugin-auth", Auth: &sftpgov1alpha1.PluginAuthOptions{Scope: 1}},
+					},
+				},
+			}
+			r := &SftpGoServerReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+
+			dep := r.deploymentForServer(server)
+			Expect(dep.Spec.Template.Spec.InitContainers).To(HaveLen(1))
+			Expect(dep.Spec.Template.Spec.InitContainers[0].Command).To(Equal([]string{"cp", "/bin/notify", "/opt/sftpgo/plugins/notify"}))
+
+			cfg := r.configMapForServer(server).Data["sftpgo.json"]
+			Expect(cfg).To(ContainSubstring(`"cmd":"/opt/sftpgo/plugins/notify"`))
+			Expect(cfg).To(ContainSubstring(`"cmd":"/usr/local/bin/sftpgo-plugin-auth"`))
+			Expect(cfg).To(ContainSubstring(`"auth_options":{"scope":1}`))
+		})
+	})
+
+	Context("When the defender is configured", func() {
+		It("should render only the defender keys SFTPGO supports", func() {
+			server := &sftpgov1alpha1.SftpGoServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "with-defender", Namespace: "default"},
+				Spec: sftpgov1alpha1.SftpGoServerSpec{
+					Config: sftpgov1alpha1.SFTPGOConfig{
+						Defender: &sftpgov1alpha1.DefenderConfig{
+							Enabled: true, Driver: "provider", BanTime: 60, BanTimeIncrement: 50, Threshold: 15,
+							ScoreInvalid: 2, ScoreValid: 1, ScoreLimitExceeded: 3, ScoreNoAuth: 1, ObservationTime: 30,
+							EntriesSoftLimit: 100, EntriesHardLimit: 150,
+							Safelist: []string{"10.0.0.0/8", "192.0.2.1"},
+						},
+						RateLimiters: []sftpgov1alpha1.RateLimiterConfig{
+							{Average: 10, Type: "global", Protocols: []string{"SSH"}},
+						},
+					},
+				},
+			}
+			r := &SftpGoServerReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+
+			cm := r.configMapForServer(server)
+			Expect(cm.Data).To(HaveLen(1))
+			var cfg struct {
+				Common struct {
+					Defender map[string]any `json:"defender"`
+				} `json:"common"`
+			}
+			Expect(json.Unmarshal([]byte(cm.Data["sftpgo.json"]), &cfg)).To(Succeed())
+			keys := make([]string, 0, len(cfg.Common.Defender))
+			for key := range cfg.Common.Defender {
+				keys = append(keys, key)
+			}
+			Expect(keys).To(ConsistOf("enabled", "driver", "ban_time", "ban_time_increment", "threshold",
+				"score_invalid", "score_valid", "score_limit_exceeded", "score_no_auth", "observation_time",
+				"entries_soft_limit", "entries_hard_limit"))
+			Expect(cm.Data["sftpgo.json"]).To(ContainSubstring(`"rate_limiters":[{"average":10,"type":1,"protocols":["SSH"]`))
+		})
+
+		It("should sync the safelist and blocklist to the defender IP list", func() {
+			d := &sftpgov1alpha1.DefenderConfig{
+				Safelist:  []string{"10.0.0.0/8", "192.0.2.1"},
+				Blocklist: []string{"2001:db8::1", "198.51.100.0/24"},
+			}
+			managed := func(network string, mode int) sftpgo.IPListEntry {
+				return sftpgo.IPListEntry{IPOrNet: network, Description: defenderListDescription,
+					Type: sftpgo.IPListTypeDefender, Mode: mode}
+			}
+			current := []sftpgo.IPListEntry{
+				managed("10.0.0.0/8", sftpgo.IPListModeAllow),
+				managed("198.51.100.0/24", sftpgo.IPListModeAllow),
+				managed("203.0.113.0/24", sftpgo.IPListModeDeny),
+				{IPOrNet: "192.0.2.1/32", Type: sftpgo.IPListTypeDefender, Mode: sftpgo.IPListModeDeny},
+				{IPOrNet: "172.16.0.0/12", Type: sftpgo.IPListTypeDefender, Mode: sftpgo.IPListModeDeny},
+			}
+			add, remove := defenderListChanges(d, current)
+			Expect(remove).To(ConsistOf("198.51.100.0/24", "203.0.113.0/24", "192.0.2.1/32"))
+			Expect(add).To(Equal([]sftpgo.IPListEntry{
+				managed("192.0.2.1/32", sftpgo.IPListModeAllow),
+				managed("198.51.100.0/24", sftpgo.IPListModeDeny),
+				managed("2001:db8::1/128", sftpgo.IPListModeDeny),
+			}))
+
+			var requests []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				switch {
+				case req.URL.Path == "/api/v2/token":
+					_, _ = w.Write([]byte(`{"access_token":"t"}`))
+				case req.Method == http.MethodGet && req.URL.Path == "/api/v2/iplists/2":
+					_ = json.NewEncoder(w).Encode(current)
+				case req.Method == http.MethodPost && req.URL.Path == "/api/v2/iplists/2":
+					var entry sftpgo.IPListEntry
+					Expect(json.NewDecoder(req.Body).Decode(&entry)).To(Succeed())
+					requests = append(requests, "add "+entry.IPOrNet)
+					w.WriteHeader(http.StatusCreated)
+				case req.Method == http.MethodDelete:
+					requests = append(requests, "delete "+req.URL.EscapedPath())
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer srv.Close()
+			Expect(syncDefenderList(sftpgo.NewClient(srv.URL, "", ""), d)).To(Succeed())
+			Expect(requests).To(ContainElements(
+				"delete /api/v2/iplists/2/203.0.113.0%2F24",
+				"add 2001:db8::1/128",
+			))
+			Expect(requests).To(HaveLen(6))
+		})
+	})
+
+	Context("When the PROXY protocol is configured", func() {
+		It("should flag a load balancer that does not send the PROXY header", func() {
+			spec := &sftpgov1alpha1.SftpGoServerSpec{
+				Service:       &sftpgov1alpha1.ServiceConfig{Type: corev1.ServiceTypeLoadBalancer},
+				ProxyProtocol: &sftpgov1alpha1.ProxyProtocolConfig{Mode: "required", Allowed: []string{"10.0.0.0/8"}},
+			}
+			cond := proxyProtocolCondition(spec)
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal("ProxyHeaderNotEnabled"))
+
+			spec.Service.Annotations = map[string]string{"service.beta.kubernetes.io/aws-load-balancer-proxy-protocol": "*"}
+			cond = proxyProtocolCondition(spec)
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+
+			spec.ProxyProtocol.Mode = "off"
+			cond = proxyProtocolCondition(spec)
+			Expect(cond.Reason).To(Equal("ProxyHeaderIgnored"))
+		})
+	})
+
+	Context("When server authentication is configured", func() {
+		It("should derive the OIDC redirect URL from the ingress and load the LDAP plugin", func() {
+			server := &sftpgov1alpha1.SftpGoServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "with-auth", Namespace: "default"},
+				Spec: sftpgov1alpha1.SftpGoServerSpec{
+					Ingress: &sftpgov1alpha1.IngressConfig{Host: "sftp.example.com", TLSSecretName: "sftp-tls"},
+					Auth: &sftpgov1alpha1.AuthConfig{
+						OIDC: &sftpgov1alpha1.OIDCConfig{
+							ConfigURL:       "https://idp.example.com",
+							ClientID:        "sftpgo",
+							ClientSecretRef: sftpgov1alpha1.SecretRef{Name: "oidc", Key: "secret"},
+						},
+						LDAP: &sftpgov1alpha1.LDAPAuthConfig{URLs: []string{"ldaps://ldap.example.com"}, BaseDN: "dc=example,dc=com"},
+					},
+				},
+			}
+			r := &SftpGoServerReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+
+			cfg := r.configMapForServer(server).Data["sftpgo.json"]
+			Expect(cfg).To(ContainSubstring(`"redirect_base_url":"https://sftp.example.com"`))
+			Expect(cfg).NotTo(ContainSubstring(`client_secret`))
+			Expect(cfg).To(ContainSubstring(`"cmd":"/usr/local/bin/sftpgo-plugin-auth"`))
+
+			dep := r.deploymentForServer(server)
+			Expect(dep.Spec.Template.Spec.Containers[0].Image).To(Equal("docker.io/drakkan/sftpgo:plugins"))
+		})
+	})
+
+	Context("When logging is configured", func() {
+		It("should pass the log flags, add the shipper and roll the pods on change", func() {
+			server := &sftpgov1alpha1.SftpGoServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "with-logging", Namespace: "default"},
+				Spec: sftpgov1alpha1.SftpGoServerSpec{
+					Logging: &sftpgov1alpha1.LoggingConfig{Level: "info", Format: "json"},
+				},
+			}
+			r := &SftpGoServerReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+
+			dep := r.deploymentForServer(server)
+			containers := dep.Spec.Template.Spec.Containers
+			Expect(containers).To(HaveLen(2))
+			Expect(containers[0].Args).To(ContainElements("--log-level", "info", "--log-file-path", "/var/log/sftpgo/sftpgo.log"))
+			Expect(containers[1].Name).To(Equal("log-shipper"))
+			Expect(r.configMapForServer(server).Data).To(HaveKey("fluent-bit.conf"))
+
+			hash := dep.Spec.Template.Annotations["sftpgo.sftpgo.io/config-hash"]
+			server.Spec.Logging.Audit = &sftpgov1alpha1.AuditLogConfig{Transfers: ptr.To(false)}
+			Expect(r.deploymentForServer(server).Spec.Template.Annotations["sftpgo.sftpgo.io/config-hash"]).NotTo(Equal(hash))
+		})
+	})
+
+	Context("When user management is exclusive", func() {
+		ctx := context.Background()
+
+		It("should only prune the unprotected users no SftpGoUser manages", func() {
+			users := []sftpgo.UserPayload{{Username: "alice"}, {Username: "bob"}, {Username: "svc-backup"}, {Username: "carol"}}
+			managed := map[string]bool{"alice": true}
+			pruning := &sftpgov1alpha1.UserPruningConfig{ProtectedUsers: []string{"svc-*"}}
+
+			remove, keep := unmanagedUsers(users, managed, pruning)
+			Expect(remove).To(Equal([]string{"bob", "carol"}))
+			Expect(keep).To(Equal([]string{"svc-backup"}))
+
+			pruning.DryRun = true
+			remove, keep = unmanagedUsers(users, managed, pruning)
+			Expect(remove).To(BeEmpty())
+			Expect(keep).To(Equal([]string{"bob", "svc-backup", "carol"}))
+		})
+
+		It("should find the SftpGoUsers of the server in every namespace", func() {
+			server := &sftpgov1alpha1.SftpGoServer{ObjectMeta: metav1.ObjectMeta{Name: "exclusive", Namespace: "default"}}
+			for _, u := range []*sftpgov1alpha1.SftpGoUser{
+				{ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "default"},
+					Spec: sftpgov1alpha1.SftpGoUserSpec{Username: "local", HomeDir: "/srv/local", ServerRef: sftpgov1alpha1.ServerRef{Name: "exclusive"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "remote", Namespace: "tenant"},
+					Spec: sftpgov1alpha1.SftpGoUserSpec{Username: "remote", HomeDir: "/srv/remote", ServerRef: sftpgov1alpha1.ServerRef{Name: "exclusive", Namespace: "default"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "tenant"},
+					Spec: sftpgov1alpha1.SftpGoUserSpec{Username: "other", HomeDir: "/srv/other", ServerRef: sftpgov1alpha1.ServerRef{Name: "exclusive"}}},
+			} {
+				Expect(k8sClient.Create(ctx, u)).To(Succeed())
+			}
+			r := &SftpGoServerReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			managed, err := r.managedUsernames(ctx, server)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(managed).To(Equal(map[string]bool{"local": true, "remote": true}))
+
+			Expect(userScanDue(nil, time.Minute)).To(BeTrue())
+			checked := metav1.Now()
+			Expect(userScanDue(&sftpgov1alpha1.UnmanagedUsersStatus{LastChecked: &checked}, time.Minute)).To(BeFalse())
+		})
+	})
 })
@@ -0,0 +1,220 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+	"github.com/sftpgo/sftpgo-operator/internal/sftpgo"
+)
+
+const (
+	// defenderListDescription marks the entries of the SFTPGO defender IP
+	// list synced from spec.config.defender
+	defenderListDescription = "Managed by sftpgo-operator"
+
+	// defenderStatusInterval is how often banned hosts are refreshed in status
+	defenderStatusInterval = time.Minute
+	// defenderStatusMaxHosts caps the number of hosts listed in status
+	defenderStatusMaxHosts = 20
+)
+
+// defenderJSON is the SFTPGO "common.defender" config section
+type defenderJSON struct {
+	Enabled            bool   `json:"enabled"`
+	Driver             string `json:"driver,omitempty"`
+	BanTime            int    `json:"ban_time,omitempty"`
+	BanTimeIncrement   int    `json:"ban_time_increment,omitempty"`
+	Threshold          int    `json:"threshold,omitempty"`
+	ScoreInvalid       int    `json:"score_invalid,omitempty"`
+	ScoreValid         int    `json:"score_valid,omitempty"`
+	ScoreLimitExceeded int    `json:"score_limit_exceeded,omitempty"`
+	ScoreNoAuth        int    `json:"score_no_auth,omitempty"`
+	ObservationTime    int    `json:"observation_time,omitempty"`
+	EntriesSoftLimit   int    `json:"entries_soft_limit,omitempty"`
+	EntriesHardLimit   int    `json:"entries_hard_limit,omitempty"`
+}
+
+// rateLimiterJSON is an entry of the SFTPGO "common.rate_limiters" config section
+type rateLimiterJSON struct {
+	Average                int64    `json:"average"`
+	Period                 int64    `json:"period,omitempty"`
+	Burst                  int      `json:"burst,omitempty"`
+	Type                   int      `json:"type,omitempty"`
+	Protocols              []string `json:"protocols,omitempty"`
+	GenerateDefenderEvents bool     `json:"generate_defender_events"`
+	EntriesSoftLimit       int      `json:"entries_soft_limit,omitempty"`
+	EntriesHardLimit       int      `json:"entries_hard_limit,omitempty"`
+}
+
+func defenderConfig(d *sftpgov1alpha1.DefenderConfig) *defenderJSON {
+	if d == nil {
+		return nil
+	}
+	out := &defenderJSON{
+		Enabled:            d.Enabled,
+		Driver:             d.Driver,
+		BanTime:            d.BanTime,
+		BanTimeIncrement:   d.BanTimeIncrement,
+		Threshold:          d.Threshold,
+		ScoreInvalid:       d.ScoreInvalid,
+		ScoreValid:         d.ScoreValid,
+		ScoreLimitExceeded: d.ScoreLimitExceeded,
+		ScoreNoAuth:        d.ScoreNoAuth,
+		ObservationTime:    d.ObservationTime,
+		EntriesSoftLimit:   d.EntriesSoftLimit,
+		EntriesHardLimit:   d.EntriesHardLimit,
+	}
+	return out
+}
+
+func rateLimitersConfig(limiters []sftpgov1alpha1.RateLimiterConfig) []rateLimiterJSON {
+	var out []rateLimiterJSON
+	for _, l := range limiters {
+		rl := rateLimiterJSON{
+			Average:                l.Average,
+			Period:                 l.Period,
+			Burst:                  l.Burst,
+			Protocols:              l.Protocols,
+			GenerateDefenderEvents: l.GenerateDefenderEvents,
+			EntriesSoftLimit:       l.EntriesSoftLimit,
+			EntriesHardLimit:       l.EntriesHardLimit,
+		}
+		switch l.Type {
+		case "global":
+			rl.Type = 1
+		case "source", "":
+			rl.Type = 2
+		}
+		out = append(out, rl)
+	}
+	return out
+}
+
+// defenderListNetwork returns an IP address or CIDR network the way SFTPGO
+// stores it in its IP lists, addresses as /32 or /128 networks
+func defenderListNetwork(entry string) string {
+	if strings.Contains(entry, "/") {
+		return entry
+	}
+	if ip := net.ParseIP(entry); ip != nil && ip.To4() == nil {
+		return entry + "/128"
+	}
+	return entry + "/32"
+}
+
+// defenderListChanges returns the entries to add to the SFTPGO defender IP
+// list, and the networks to remove from it, for its entries to match the
+// safelist and blocklist. Entries added by other means are left alone unless
+// they list a network of the spec.
+func defenderListChanges(d *sftpgov1alpha1.DefenderConfig, current []sftpgo.IPListEntry) ([]sftpgo.IPListEntry, []string) {
+	want := map[string]int{}
+	for _, e := range d.Blocklist {
+		want[defenderListNetwork(e)] = sftpgo.IPListModeDeny
+	}
+	// An address in both lists is never banned
+	for _, e := range d.Safelist {
+		want[defenderListNetwork(e)] = sftpgo.IPListModeAllow
+	}
+
+	var remove []string
+	for _, entry := range current {
+		mode, ok := want[entry.IPOrNet]
+		switch {
+		case ok && mode == entry.Mode && entry.Description == defenderListDescription && entry.Protocols == 0:
+			delete(want, entry.IPOrNet)
+		case ok || entry.Description == defenderListDescription:
+			remove = append(remove, entry.IPOrNet)
+		}
+	}
+	add := make([]sftpgo.IPListEntry, 0, len(want))
+	for network, mode := range want {
+		add = append(add, sftpgo.IPListEntry{
+			IPOrNet:     network,
+			Description: defenderListDescription,
+			Type:        sftpgo.IPListTypeDefender,
+			Mode:        mode,
+		})
+	}
+	sort.Slice(add, func(i, j int) bool { return add[i].IPOrNet < add[j].IPOrNet })
+	return add, remove
+}
+
+// syncDefenderList makes the SFTPGO defender IP list, kept in the data
+// provider since SFTPGO 2.5, match the safelist and blocklist
+func syncDefenderList(c *sftpgo.Client, d *sftpgov1alpha1.DefenderConfig) error {
+	current, err := c.ListIPList(sftpgo.IPListTypeDefender)
+	if err != nil {
+		return err
+	}
+	add, remove := defenderListChanges(d, current)
+	for _, network := range remove {
+		if err := c.DeleteIPListEntry(sftpgo.IPListTypeDefender, network); err != nil {
+			return err
+		}
+	}
+	for _, entry := range add {
+		if err := c.AddIPListEntry(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defenderStatusDue reports whether the defender summary should be refreshed.
+// Status updates retrigger a reconcile, so the API is queried at most once
+// per defenderStatusInterval.
+func defenderStatusDue(status *sftpgov1alpha1.DefenderStatus) bool {
+	return status == nil || status.LastChecked == nil || time.Since(status.LastChecked.Time) >= defenderStatusInterval
+}
+
+// defenderStatus queries the SFTPGO defender and summarises the banned hosts
+func defenderStatus(c *sftpgo.Client) (*sftpgov1alpha1.DefenderStatus, error) {
+	hosts, err := c.GetDefenderHosts()
+	if err != nil {
+		return nil, err
+	}
+	var banned []sftpgo.DefenderHost
+	for _, h := range hosts {
+		if h.Banned() {
+			banned = append(banned, h)
+		}
+	}
+	sort.Slice(banned, func(i, j int) bool {
+		return banned[i].BanTime.After(banned[j].BanTime)
+	})
+
+	now := metav1.Now()
+	status := &sftpgov1alpha1.DefenderStatus{
+		BannedHosts: len(banned),
+		LastChecked: &now,
+	}
+	for i, h := range banned {
+		if i == defenderStatusMaxHosts {
+			break
+		}
+		until := metav1.NewTime(h.BanTime)
+		status.Hosts = append(status.Hosts, sftpgov1alpha1.BannedHost{IP: h.IP, BanUntil: &until})
+	}
+	return status, nil
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+const (
+	sftpgoLogsVolume = "logs"
+	sftpgoLogsDir    = "/var/log/sftpgo"
+	sftpgoLogFile    = sftpgoLogsDir + "/sftpgo.log"
+
+	fluentBitDefaultImage = "cr.fluentbit.io/fluent/fluent-bit:3.2"
+	fluentBitConfigFile   = "fluent-bit.conf"
+)
+
+// SFTPGO audit entries are identified by their "sender" field
+const (
+	transferLogSenders = "^(Upload|Download)$"
+	commandLogSenders  = "^(Rename|Rmdir|Mkdir|Symlink|Remove|Chmod|Chown|Chtimes|Truncate|Copy|SSHCommand)$"
+)
+
+// logShipper returns the effective log shipper: json logs always need one to
+// reach stdout, so the fluent-bit stdout preset is used when none is set
+func logShipper(l *sftpgov1alpha1.LoggingConfig) *sftpgov1alpha1.LogShipperConfig {
+	if l == nil {
+		return nil
+	}
+	if l.Shipper != nil {
+		return l.Shipper
+	}
+	if l.Format == "json" {
+		return &sftpgov1alpha1.LogShipperConfig{Preset: "fluent-bit", Output: "stdout"}
+	}
+	return nil
+}
+
+// logFileEnabled reports whether SFTPGO writes its logs to the shared logs volume
+func logFileEnabled(l *sftpgov1alpha1.LoggingConfig) bool {
+	return logShipper(l) != nil
+}
+
+// loggingArgs returns the "sftpgo serve" log flags
+func loggingArgs(l *sftpgov1alpha1.LoggingConfig) []string {
+	if l == nil {
+		return nil
+	}
+	var args []string
+	if l.Level != "" {
+		args = append(args, "--log-level", l.Level)
+	}
+	if logFileEnabled(l) {
+		maxSize, maxBackups := 10, 5
+		if l.MaxSize > 0 {
+			maxSize = l.MaxSize
+		}
+		if l.MaxBackups > 0 {
+			maxBackups = l.MaxBackups
+		}
+		args = append(args,
+			"--log-file-path", sftpgoLogFile,
+			"--log-max-size", strconv.Itoa(maxSize),
+			"--log-max-backups", strconv.Itoa(maxBackups),
+		)
+	} else {
+		args = append(args, "--log-file-path", "")
+	}
+	if l.UTCTime {
+		args = append(args, "--log-utc-time")
+	}
+	return args
+}
+
+// fluentBitConfig renders the fluent-bit configuration tailing the SFTPGO log
+// file, dropping the audit entries that are turned off
+func fluentBitConfig(l *sftpgov1alpha1.LoggingConfig) string {
+	shipper := logShipper(l)
+	var b strings.Builder
+	b.WriteString(`[SERVICE]
+    Flush        1
+    Log_Level    warn
+    Parsers_File /fluent-bit/etc/parsers.conf
+
+[INPUT]
+    Name         tail
+    Path         ` + sftpgoLogFile + `
+    Parser       json
+    Tag          sftpgo
+    Refresh_Interval 5
+    Skip_Long_Lines  On
+`)
+	if l.Audit != nil && l.Audit.Transfers != nil && !*l.Audit.Transfers {
+		b.WriteString(`
+[FILTER]
+    Name         grep
+    Match        sftpgo
+    Exclude      sender ` + transferLogSenders + `
+`)
+	}
+	if l.Audit != nil && l.Audit.Commands != nil && !*l.Audit.Commands {
+		b.WriteString(`
+[FILTER]
+    Name         grep
+    Match        sftpgo
+    Exclude      sender ` + commandLogSenders + `
+`)
+	}
+
+	tls := "Off"
+	if shipper.TLS {
+		tls = "On"
+	}
+	switch shipper.Output {
+	case "forward":
+		fmt.Fprintf(&b, `
+[OUTPUT]
+    Name         forward
+    Match        *
+    Host         %s
+    Port         %d
+    tls          %s
+`, shipper.Host, portOr(shipper.Port, 24224), tls)
+	case "http":
+		uri := shipper.URI
+		if uri == "" {
+			uri = "/"
+		}
+		fmt.Fprintf(&b, `
+[OUTPUT]
+    Name         http
+    Match        *
+    Host         %s
+    Port         %d
+    URI          %s
+    Format       json_lines
+    tls          %s
+`, shipper.Host, portOr(shipper.Port, 80), uri, tls)
+	default:
+		b.WriteString(`
+[OUTPUT]
+    Name         stdout
+    Match        *
+    Format       json_lines
+`)
+	}
+	return b.String()
+}
+
+func portOr(p, def int32) int32 {
+	if p > 0 {
+		return p
+	}
+	return def
+}
+
+// logShipperContainer returns the log shipping sidecar, or nil if none is configured
+func logShipperContainer(l *sftpgov1alpha1.LoggingConfig) *corev1.Container {
+	shipper := logShipper(l)
+	if shipper == nil {
+		return nil
+	}
+	image := shipper.Image
+	if image == "" {
+		image = fluentBitDefaultImage
+	}
+	c := &corev1.Container{
+		Name:  "log-shipper",
+		Image: image,
+		Args:  []string{"-c", sftpgoConfigDir + "/" + fluentBitConfigFile},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "config", MountPath: sftpgoConfigDir, ReadOnly: true},
+			{Name: sftpgoLogsVolume, MountPath: sftpgoLogsDir, ReadOnly: true},
+		},
+	}
+	if shipper.Resources != nil {
+		c.Resources = *shipper.Resources
+	}
+	return c
+}
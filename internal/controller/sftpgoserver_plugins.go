@@ -0,0 +1,217 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+const (
+	sftpgoPluginsVolume = "plugins"
+	sftpgoPluginsDir    = "/opt/sftpgo/plugins"
+)
+
+// sftpgoImageVariants maps spec.imageVariant to the default image tag
+var sftpgoImageVariants = map[string]string{
+	"standard":   "docker.io/drakkan/sftpgo:latest",
+	"alpine":     "docker.io/drakkan/sftpgo:alpine",
+	"distroless": "docker.io/drakkan/sftpgo:distroless-slim",
+	"plugins":    "docker.io/drakkan/sftpgo:plugins",
+}
+
+// pluginJSON is the SFTPGO "plugins" config entry
+type pluginJSON struct {
+	Type            string                 `json:"type"`
+	NotifierOptions *pluginNotifierJSON    `json:"notifier_options,omitempty"`
+	KMSOptions      *pluginKMSJSON         `json:"kms_options,omitempty"`
+	AuthOptions     *pluginAuthOptionsJSON `json:"auth_options,omitempty"`
+	Cmd             string                 `json:"cmd"`
+	Args            []string               `json:"args"`
+	SHA256Sum       string                 `json:"sha256sum"`
+	AutoMTLS        bool                   `json:"auto_mtls"`
+	EnvVars         []string               `json:"env_vars,omitempty"`
+}
+
+type pluginNotifierJSON struct {
+	FSEvents          []string `json:"fs_events"`
+	ProviderEvents    []string `json:"provider_events"`
+	ProviderObjects   []string `json:"provider_objects"`
+	RetryMaxTime      int      `json:"retry_max_time"`
+	RetryQueueMaxSize int      `json:"retry_queue_max_size"`
+}
+
+type pluginKMSJSON struct {
+	Scheme          string `json:"scheme"`
+	EncryptedStatus string `json:"encrypted_status"`
+}
+
+type pluginAuthOptionsJSON struct {
+	Scope int `json:"scope"`
+}
+
+// pluginCmd returns the path SFTPGO executes for a plugin
+func pluginCmd(p sftpgov1alpha1.PluginConfig) string {
+	if p.Image != "" {
+		return path.Join(sftpgoPluginsDir, p.Name)
+	}
+	return p.Binary
+}
+
+// pluginsConfig renders the SFTPGO "plugins" section
+func pluginsConfig(plugins []sftpgov1alpha1.PluginConfig) string {
+	out := make([]pluginJSON, 0, len(plugins))
+	for _, p := range plugins {
+		pj := pluginJSON{
+			Type:      p.Type,
+			Cmd:       pluginCmd(p),
+			Args:      p.Args,
+			SHA256Sum: p.SHA256Sum,
+			AutoMTLS:  p.AutoMTLS,
+		}
+		if pj.Args == nil {
+			pj.Args = []string{}
+		}
+		for _, e := range p.Env {
+			pj.EnvVars = append(pj.EnvVars, e.Name)
+		}
+		if p.Notifier != nil {
+			pj.NotifierOptions = &pluginNotifierJSON{
+				FSEvents:          nonNil(p.Notifier.FSEvents),
+				ProviderEvents:    nonNil(p.Notifier.ProviderEvents),
+				ProviderObjects:   nonNil(p.Notifier.ProviderObjects),
+				RetryMaxTime:      p.Notifier.RetryMaxTime,
+				RetryQueueMaxSize: p.Notifier.RetryQueueMaxSize,
+			}
+		}
+		if p.KMS != nil {
+			pj.KMSOptions = &pluginKMSJSON{
+				Scheme:          p.KMS.Scheme,
+				EncryptedStatus: p.KMS.EncryptedStatus,
+			}
+		}
+		if p.Auth != nil {
+			pj.AuthOptions = &pluginAuthOptionsJSON{Scope: p.Auth.Scope}
+		}
+		out = append(out, pj)
+	}
+	b, _ := json.Marshal(out)
+	return string(b)
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// pluginInitContainers returns one init container per plugin shipped in its own
+// image, copying the plugin binary into the shared plugins volume with the cp
+// of the plugin image
+func pluginInitContainers(plugins []sftpgov1alpha1.PluginConfig) []corev1.Container {
+	var out []corev1.Container
+	for _, p := range plugins {
+		if p.Image == "" {
+			continue
+		}
+		out = append(out, corev1.Container{
+			Name:            "plugin-" + p.Name,
+			Image:           p.Image,
+			ImagePullPolicy: p.ImagePullPolicy,
+			Command:         []string{"cp", p.Binary, path.Join(sftpgoPluginsDir, p.Name)},
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: sftpgoPluginsVolume, MountPath: sftpgoPluginsDir},
+			},
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		})
+	}
+	return out
+}
+
+// pluginEnv returns the env variables declared by the plugins, passed to the
+// SFTPGO container so that SFTPGO can forward them to the plugin processes
+func pluginEnv(plugins []sftpgov1alpha1.PluginConfig) []corev1.EnvVar {
+	var out []corev1.EnvVar
+	for _, p := range plugins {
+		out = append(out, p.Env...)
+	}
+	return out
+}
+
+// pluginsCondition inspects the server pods and reports whether the plugins
+// were installed and loaded. SFTPGO refuses to start when a configured plugin
+// cannot be loaded, so a crashing sftpgo container mentioning "plugin" in its
+// termination message is reported as a load failure.
+func pluginsCondition(plugins []sftpgov1alpha1.PluginConfig, pods []corev1.Pod) metav1.Condition {
+	cond := metav1.Condition{
+		Type:    "PluginsReady",
+		Status:  metav1.ConditionTrue,
+		Reason:  "PluginsLoaded",
+		Message: fmt.Sprintf("%d plugin(s) configured", len(plugins)),
+	}
+	for _, pod := range pods {
+		for _, cs := range pod.Status.InitContainerStatuses {
+			if !strings.HasPrefix(cs.Name, "plugin-") {
+				continue
+			}
+			if msg, failed := containerFailure(cs); failed {
+				if strings.Contains(msg, `"cp"`) {
+					msg += " (the plugin image must provide cp, use an image with a shell or the plugins variant)"
+				}
+				cond.Status = metav1.ConditionFalse
+				cond.Reason = "PluginInstallFailed"
+				cond.Message = fmt.Sprintf("pod %s: %s: %s", pod.Name, cs.Name, msg)
+				return cond
+			}
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.Name != "sftpgo" {
+				continue
+			}
+			msg, failed := containerFailure(cs)
+			if failed && strings.Contains(strings.ToLower(msg), "plugin") {
+				cond.Status = metav1.ConditionFalse
+				cond.Reason = "PluginLoadFailed"
+				cond.Message = fmt.Sprintf("pod %s: %s", pod.Name, msg)
+				return cond
+			}
+		}
+	}
+	return cond
+}
+
+// containerFailure returns the last failure message of a container, if any
+func containerFailure(cs corev1.ContainerStatus) (string, bool) {
+	if t := cs.State.Terminated; t != nil && t.ExitCode != 0 {
+		return strings.TrimSpace(t.Reason + " " + t.Message), true
+	}
+	if w := cs.State.Waiting; w != nil && w.Reason != "" && w.Reason != "PodInitializing" && w.Reason != "ContainerCreating" {
+		if t := cs.LastTerminationState.Terminated; t != nil && t.ExitCode != 0 {
+			return strings.TrimSpace(w.Reason + ": " + t.Message), true
+		}
+		return strings.TrimSpace(w.Reason + " " + w.Message), true
+	}
+	return "", false
+}
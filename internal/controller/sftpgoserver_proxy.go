@@ -0,0 +1,107 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+// proxyProtocolModes maps spec.proxyProtocol.mode to the SFTPGO proxy_protocol value
+var proxyProtocolModes = map[string]int{
+	"off":      0,
+	"optional": 1,
+	"required": 2,
+}
+
+// proxyProtocolAnnotations are the Service annotations known to make a cloud
+// load balancer send the PROXY header, with a matcher for the enabling value
+var proxyProtocolAnnotations = map[string]func(string) bool{
+	"service.beta.kubernetes.io/aws-load-balancer-proxy-protocol": func(v string) bool { return v == "*" },
+	"service.beta.kubernetes.io/aws-load-balancer-target-group-attributes": func(v string) bool {
+		return strings.Contains(strings.ReplaceAll(v, " ", ""), "proxy_protocol_v2.enabled=true")
+	},
+	"service.beta.kubernetes.io/do-loadbalancer-enable-proxy-protocol": isTrue,
+	"load-balancer.hetzner.cloud/uses-proxyprotocol":                   isTrue,
+	"service.beta.kubernetes.io/scw-loadbalancer-proxy-protocol-v2":    isTrue,
+	"service.beta.kubernetes.io/linode-loadbalancer-proxy-protocol":    func(v string) bool { return v == "v1" || v == "v2" },
+	"service.beta.kubernetes.io/ovh-loadbalancer-proxy-protocol":       func(v string) bool { return v == "v1" || v == "v2" },
+}
+
+func isTrue(v string) bool {
+	return strings.EqualFold(v, "true")
+}
+
+// serviceSendsProxyHeader reports whether the Service annotations enable the
+// PROXY protocol on the cloud load balancer, and which annotation does it
+func serviceSendsProxyHeader(svc *sftpgov1alpha1.ServiceConfig) (string, bool) {
+	if svc == nil {
+		return "", false
+	}
+	for k, v := range svc.Annotations {
+		if match, ok := proxyProtocolAnnotations[k]; ok && match(v) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// proxyProtocolCondition checks the PROXY protocol settings against the
+// Service type and annotations. Mismatches do not block the reconcile since
+// the proxy may live outside the cluster; they are reported as a condition.
+func proxyProtocolCondition(spec *sftpgov1alpha1.SftpGoServerSpec) metav1.Condition {
+	mode := "off"
+	if spec.ProxyProtocol != nil {
+		mode = spec.ProxyProtocol.Mode
+	}
+	svcType := corev1.ServiceTypeClusterIP
+	if spec.Service != nil && spec.Service.Type != "" {
+		svcType = spec.Service.Type
+	}
+	annotation, sendsHeader := serviceSendsProxyHeader(spec.Service)
+
+	cond := metav1.Condition{
+		Type:   "ProxyProtocolConsistent",
+		Status: metav1.ConditionTrue,
+		Reason: "Consistent",
+	}
+	switch {
+	case mode == "off" && sendsHeader:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "ProxyHeaderIgnored"
+		cond.Message = "Service annotation " + annotation + " enables the PROXY protocol on the load balancer but proxyProtocol.mode is off: connections will fail"
+	case mode != "off" && len(spec.ProxyProtocol.Allowed) == 0:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "NoAllowedProxies"
+		cond.Message = "proxyProtocol.allowed is empty: PROXY headers will be ignored"
+		if mode == "required" {
+			cond.Message = "proxyProtocol.allowed is empty: all connections will be refused"
+		}
+	case mode == "required" && svcType == corev1.ServiceTypeLoadBalancer && !sendsHeader:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "ProxyHeaderNotEnabled"
+		cond.Message = "proxyProtocol.mode is required but no known Service annotation enables the PROXY protocol on the load balancer"
+	case mode == "off" && svcType != corev1.ServiceTypeClusterIP && spec.Service.ExternalTrafficPolicy != corev1.ServiceExternalTrafficPolicyLocal:
+		cond.Reason = "ClientIPNotPreserved"
+		cond.Message = "Client IPs are only preserved with the PROXY protocol or externalTrafficPolicy Local"
+	}
+	return cond
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+	"github.com/sftpgo/sftpgo-operator/internal/sftpgo"
+)
+
+const (
+	// userManagementExclusive prunes the SFTPGO users no SftpGoUser manages
+	userManagementExclusive = "exclusive"
+
+	// defaultUserScanInterval is how often unmanaged users are looked for
+	defaultUserScanInterval = 10 * time.Minute
+	// unmanagedUsersMaxListed caps the number of users listed in status
+	unmanagedUsersMaxListed = 20
+)
+
+// userScanInterval returns how often the SFTPGO users are scanned
+func userScanInterval(pruning *sftpgov1alpha1.UserPruningConfig) time.Duration {
+	if pruning != nil && pruning.Interval != nil && pruning.Interval.Duration > 0 {
+		return pruning.Interval.Duration
+	}
+	return defaultUserScanInterval
+}
+
+// userScanDue reports whether the SFTPGO users should be scanned again.
+// Status updates retrigger a reconcile, so the API is queried at most once
+// per interval.
+func userScanDue(status *sftpgov1alpha1.UnmanagedUsersStatus, interval time.Duration) bool {
+	return status == nil || status.LastChecked == nil || time.Since(status.LastChecked.Time) >= interval
+}
+
+// protectedUser reports whether a username matches the protected users
+func protectedUser(pruning *sftpgov1alpha1.UserPruningConfig, username string) bool {
+	if pruning == nil {
+		return false
+	}
+	for _, pattern := range pruning.ProtectedUsers {
+		if ok, _ := path.Match(pattern, username); ok || pattern == username {
+			return true
+		}
+	}
+	return false
+}
+
+// unmanagedUsers splits the SFTPGO users no SftpGoUser manages into those
+// to delete and those to keep: protected users, or all of them in dry run
+func unmanagedUsers(users []sftpgo.UserPayload, managed map[string]bool,
+	pruning *sftpgov1alpha1.UserPruningConfig) (remove, keep []string) {
+	dryRun := pruning != nil && pruning.DryRun
+	for _, u := range users {
+		switch {
+		case managed[u.Username]:
+		case dryRun || protectedUser(pruning, u.Username):
+			keep = append(keep, u.Username)
+		default:
+			remove = append(remove, u.Username)
+		}
+	}
+	return remove, keep
+}
+
+// managedUsernames returns the usernames of the SftpGoUsers of a server
+func (r *SftpGoServerReconciler) managedUsernames(ctx context.Context, server *sftpgov1alpha1.SftpGoServer) (map[string]bool, error) {
+	users := &sftpgov1alpha1.SftpGoUserList{}
+	if err := r.List(ctx, users); err != nil {
+		return nil, err
+	}
+	key := types.NamespacedName{Name: server.Name, Namespace: server.Namespace}
+	managed := map[string]bool{}
+	for i := range users.Items {
+		if userServerKey(&users.Items[i]) != key {
+			continue
+		}
+		// Users the server refuses do not keep their account
+		allowed, err := serverAllowsNamespace(ctx, r.Client, server, users.Items[i].Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			managed[users.Items[i].Spec.Username] = true
+		}
+	}
+	return managed, nil
+}
+
+// pruneUnmanagedUsers looks for the SFTPGO users no SftpGoUser manages and,
+// unless in dry run, deletes the unprotected ones and closes their sessions
+func (r *SftpGoServerReconciler) pruneUnmanagedUsers(ctx context.Context, server *sftpgov1alpha1.SftpGoServer,
+	spec *sftpgov1alpha1.SftpGoServerSpec) error {
+	log := logf.FromContext(ctx)
+	username, password, err := adminCredentials(ctx, r.Client, server)
+	if err != nil {
+		return err
+	}
+	if username == "" || password == "" {
+		return fmt.Errorf("exclusive user management requires adminSecretRef")
+	}
+	c := sftpgo.NewClient(serverAPIURL(server), username, password)
+
+	// Listed before the SFTPGO users, so that a user created meanwhile has
+	// its SftpGoUser in the list
+	managed, err := r.managedUsernames(ctx, server)
+	if err != nil {
+		return err
+	}
+	users, err := c.ListUsers()
+	if err != nil {
+		return err
+	}
+
+	remove, keep := unmanagedUsers(users, managed, spec.UserPruning)
+	now := metav1.Now()
+	status := &sftpgov1alpha1.UnmanagedUsersStatus{
+		Count:       len(remove) + len(keep),
+		Users:       keep,
+		LastChecked: &now,
+	}
+	var deleted []string
+	for _, name := range remove {
+		if err := c.DeleteUser(name); err != nil {
+			log.Error(err, "Failed to delete unmanaged SFTPGO user", "username", name)
+			status.Users = append(status.Users, name)
+			continue
+		}
+		deleted = append(deleted, name)
+		r.Recorder.Eventf(server, corev1.EventTypeNormal, "UserPruned", "Deleted SFTPGO user %s, not managed by any SftpGoUser", name)
+	}
+	status.Deleted = len(deleted)
+	if len(status.Users) > unmanagedUsersMaxListed {
+		status.Users = status.Users[:unmanagedUsersMaxListed]
+	}
+
+	// Deleting a user does not end its sessions
+	if len(deleted) > 0 {
+		conns, err := c.GetConnections()
+		if err != nil {
+			log.Error(err, "Failed to list SFTPGO connections")
+		}
+		for _, name := range deleted {
+			for _, conn := range sftpgo.UserConnections(conns, name) {
+				if err := c.CloseConnection(conn.ConnectionID); err != nil {
+					log.Error(err, "Failed to close SFTPGO connection", "connection", conn.ConnectionID)
+				}
+			}
+		}
+	}
+	server.Status.UnmanagedUsers = status
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+	"github.com/sftpgo/sftpgo-operator/internal/sftpgo"
+)
+
+// serverAllowsNamespace reports whether the SftpGoUsers of a namespace may
+// use the server, and so its admin credentials and data volume
+func serverAllowsNamespace(ctx context.Context, c client.Client, server *sftpgov1alpha1.SftpGoServer, namespace string) (bool, error) {
+	if namespace == server.Namespace {
+		return true, nil
+	}
+	if server.Spec.AllowedUserNamespaces == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(server.Spec.AllowedUserNamespaces)
+	if err != nil {
+		return false, fmt.Errorf("invalid allowedUserNamespaces on %s/%s: %w", server.Namespace, server.Name, err)
+	}
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// disableRevokedUser disables the account of a user on a server that no
+// longer allows its namespace and closes its sessions, using the server's own
+// admin credentials. The account itself is deleted with the SftpGoUser.
+func (r *SftpGoUserReconciler) disableRevokedUser(ctx context.Context, user *sftpgov1alpha1.SftpGoUser, server *sftpgov1alpha1.SftpGoServer) error {
+	if user.Status.UserID == 0 || user.Status.Server != client.ObjectKeyFromObject(server).String() {
+		return nil
+	}
+	username, password, err := adminCredentials(ctx, r.Client, server)
+	if err != nil || username == "" || password == "" {
+		return err
+	}
+	c := sftpgo.NewClient(serverAPIURL(server), username, password)
+	disabled, err := r.disableUser(ctx, c, user)
+	if disabled {
+		r.Recorder.Eventf(user, corev1.EventTypeWarning, "AccessRevoked", "Disabled user %s on %s/%s, which no longer allows namespace %s",
+			syncedUsername(user), server.Namespace, server.Name, user.Namespace)
+	}
+	return err
+}
+
+// disableUser disables the SFTPGO account of the user and closes its
+// sessions, reporting whether it was enabled
+func (r *SftpGoUserReconciler) disableUser(ctx context.Context, c *sftpgo.Client, user *sftpgov1alpha1.SftpGoUser) (bool, error) {
+	existing, err := c.GetUser(syncedUsername(user))
+	if err != nil || existing == nil || existing.Status == 0 {
+		return false, err
+	}
+	existing.Status = 0
+	if _, err := c.UpdateUser(existing.Username, existing); err != nil {
+		return false, err
+	}
+	r.disconnectUser(ctx, c, user, "disabled")
+	return true, nil
+}
+
+// checkServerAccess returns an error when the server refuses users from the
+// user namespace
+func checkServerAccess(ctx context.Context, c client.Client, user *sftpgov1alpha1.SftpGoUser, server *sftpgov1alpha1.SftpGoServer) error {
+	allowed, err := serverAllowsNamespace(ctx, c, server, user.Namespace)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("SftpGoServer %s/%s does not allow users from namespace %s, see its allowedUserNamespaces",
+			server.Namespace, server.Name, user.Namespace)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+const (
+	// adoptionAnnotation decides what happens to an SFTPGO user that exists
+	// before its SftpGoUser synced: adoptionAdopt, adoptionIgnore or
+	// adoptionFail (default)
+	adoptionAnnotation = "sftpgo.sftpgo.io/adoption"
+
+	// adoptionAdopt takes the existing user over
+	adoptionAdopt = "adopt"
+	// adoptionIgnore leaves the existing user untouched and unmanaged
+	adoptionIgnore = "ignore"
+	// adoptionFail reports the existing user as an error
+	adoptionFail = "fail-if-exists"
+)
+
+// adoptionPolicy returns the adoption policy of a user
+func adoptionPolicy(user *sftpgov1alpha1.SftpGoUser) (string, error) {
+	switch policy := user.Annotations[adoptionAnnotation]; policy {
+	case "":
+		return adoptionFail, nil
+	case adoptionAdopt, adoptionIgnore, adoptionFail:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("annotation %s must be %s, %s or %s, not %q",
+			adoptionAnnotation, adoptionAdopt, adoptionIgnore, adoptionFail, policy)
+	}
+}
+
+// checkAdoption returns an error when the user may not take over the SFTPGO
+// user of the same name on the server
+func (r *SftpGoUserReconciler) checkAdoption(ctx context.Context, user *sftpgov1alpha1.SftpGoUser, server *sftpgov1alpha1.SftpGoServer) error {
+	users := &sftpgov1alpha1.SftpGoUserList{}
+	if err := r.List(ctx, users, client.MatchingFields{userServerIndex: client.ObjectKeyFromObject(server).String()}); err != nil {
+		return err
+	}
+	return validateAdoption(user, server, users.Items)
+}
+
+// validateAdoption refuses to adopt an SFTPGO user another SftpGoUser of the
+// server references, which would take the account away from it, and refuses
+// users of other namespaces unless the server allows them to adopt
+func validateAdoption(user *sftpgov1alpha1.SftpGoUser, server *sftpgov1alpha1.SftpGoServer, serverUsers []sftpgov1alpha1.SftpGoUser) error {
+	for i := range serverUsers {
+		other := &serverUsers[i]
+		if other.UID != user.UID && syncedUsername(other) == user.Spec.Username {
+			return fmt.Errorf("SFTPGO user %s is already referenced by SftpGoUser %s/%s",
+				user.Spec.Username, other.Namespace, other.Name)
+		}
+	}
+	if user.Namespace != server.Namespace && !server.Spec.AllowCrossNamespaceAdoption {
+		return fmt.Errorf("SftpGoServer %s/%s does not allow users from other namespaces to adopt existing users, see its allowCrossNamespaceAdoption",
+			server.Namespace, server.Name)
+	}
+	return nil
+}
+
+// managesUser reports whether the SFTPGO user was created or adopted by the
+// operator, on its server or on the one it is being moved from: only such
+// users are updated and deleted
+func managesUser(user *sftpgov1alpha1.SftpGoUser) bool {
+	return user.Status.UserID != 0 || user.Status.MovingFromUserID != 0 ||
+		syncedWithoutUserID(user, syncedServerKey(user).String())
+}
+
+// syncedWithoutUserID reports whether the user was synced to the server by an
+// operator that did not keep the SFTPGO user ID in status yet: its account
+// is its own and must not go through adoption again
+func syncedWithoutUserID(user *sftpgov1alpha1.SftpGoUser, server string) bool {
+	return user.Status.UserID == 0 && user.Status.MovingFrom == "" && user.Status.LastSynced != nil &&
+		(user.Status.Server == "" || user.Status.Server == server)
+}
+
+// recordCreatedUser stores the ID of the SFTPGO user just created in status,
+// retrying on errors: once it is lost the operator no longer recognizes the
+// account as its own. The whole status is patched, so that a move started in
+// this reconcile is recorded with it.
+func (r *SftpGoUserReconciler) recordCreatedUser(ctx context.Context, user *sftpgov1alpha1.SftpGoUser, id int, server string) error {
+	base := user.DeepCopy()
+	base.Status = sftpgov1alpha1.SftpGoUserStatus{}
+	patch := client.MergeFrom(base)
+	user.Status.UserID = id
+	user.Status.Username = user.Spec.Username
+	user.Status.Server = server
+	return retry.OnError(retry.DefaultBackoff, func(err error) bool { return !errors.IsNotFound(err) }, func() error {
+		return r.Status().Patch(ctx, user, patch)
+	})
+}
+
+// syncedUsername returns the SFTPGO username of the user: the one it was
+// created or adopted with, the spec username before
+func syncedUsername(user *sftpgov1alpha1.SftpGoUser) string {
+	if user.Status.Username != "" {
+		return user.Status.Username
+	}
+	return user.Spec.Username
+}
+
+// validateUsername rejects a username changed after the user was created,
+// which would create a second SFTPGO user and orphan the first one. The CRD
+// also rejects the change, this covers CRDs installed without it.
+func validateUsername(user *sftpgov1alpha1.SftpGoUser) error {
+	if user.Status.Username != "" && user.Status.Username != user.Spec.Username {
+		return fmt.Errorf("username cannot change from %s to %s: create a new SftpGoUser to rename a user",
+			user.Status.Username, user.Spec.Username)
+	}
+	return nil
+}
@@ -20,35 +20,73 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
 	"github.com/sftpgo/sftpgo-operator/internal/sftpgo"
 )
 
-const sftpgoUserFinalizer = "sftpgo.sftpgo.io/user-finalizer"
+const (
+	sftpgoUserFinalizer = "sftpgo.sftpgo.io/user-finalizer"
+
+	// driftDetectionAnnotation set to "disabled" opts a user out of the periodic resync
+	driftDetectionAnnotation = "sftpgo.sftpgo.io/drift-detection"
+)
 
 // SftpGoUserReconciler reconciles a SftpGoUser object
 type SftpGoUserReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// ResyncInterval is how often synced users are compared with SFTPGO and
+	// changes made outside the operator reverted. Zero disables the resync.
+	ResyncInterval time.Duration
+
+	// UsageInterval is how often the usage of synced users is polled from
+	// SFTPGO into their status, without syncing them. Zero disables the
+	// polling.
+	UsageInterval time.Duration
+
+	// InlineSecrets is how secrets set in clear text in user specs are
+	// handled. Empty allows them.
+	InlineSecrets InlineSecretsPolicy
+
+	// HomeDirJobImage is the image of the home directory Jobs, it needs a
+	// shell with cp, tar and rm. Empty uses DefaultHomeDirJobImage.
+	HomeDirJobImage string
+
+	// SecretsHashKey keys the HMAC of the user secrets published in
+	// status.secretsHash, see LoadSecretsHashKey
+	SecretsHashKey []byte
 }
 
 // +kubebuilder:rbac:groups=sftpgo.sftpgo.io,resources=sftpgousers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=sftpgo.sftpgo.io,resources=sftpgousers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=sftpgo.sftpgo.io,resources=sftpgousers/finalizers,verbs=update
 // +kubebuilder:rbac:groups=sftpgo.sftpgo.io,resources=sftpgoservers,verbs=get;list;watch
-// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="",resources=pods;persistentvolumeclaims,verbs=get;list;watch
 
 func (r *SftpGoUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
@@ -72,9 +110,21 @@ func (r *SftpGoUserReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	// Handle deletion - remove user from SFTPGO
 	if !user.GetDeletionTimestamp().IsZero() {
-		if err := r.deleteUserFromSFTPGO(ctx, user); err != nil {
-			log.Error(err, "Failed to delete user from SFTPGO")
-			return ctrl.Result{}, err
+		// Users the operator never created nor adopted are left in place
+		if managesUser(user) {
+			if err := r.deleteUserFromSFTPGO(ctx, user); err != nil {
+				log.Error(err, "Failed to delete user from SFTPGO")
+				return ctrl.Result{}, err
+			}
+			done, err := r.cleanupHomeDir(ctx, user)
+			if err != nil {
+				log.Error(err, "Failed to clean up home directory")
+				r.Recorder.Eventf(user, corev1.EventTypeWarning, "HomeDirCleanupFailed", "Home directory cleanup failed: %v", err)
+				return ctrl.Result{}, err
+			}
+			if !done {
+				return ctrl.Result{RequeueAfter: homeDirJobPoll}, nil
+			}
 		}
 		controllerutil.RemoveFinalizer(user, sftpgoUserFinalizer)
 		if err := r.Update(ctx, user); err != nil {
@@ -83,21 +133,92 @@ func (r *SftpGoUserReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, nil
 	}
 
-	// Resolve ServerRef namespace
-	ns := user.Spec.ServerRef.Namespace
-	if ns == "" {
-		ns = user.Namespace
+	if err := validateUsername(user); err != nil {
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "ValidationError",
+			Message: err.Error(),
+		})
+		user.Status.Phase = "Error"
+		_ = r.Status().Update(ctx, user)
+		return ctrl.Result{}, nil
+	}
+
+	// Secrets set in clear text in the spec are refused or moved to a Secret
+	if secrets := inlineSecrets(user); len(secrets) > 0 {
+		switch r.InlineSecrets {
+		case InlineSecretsReject:
+			meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+				Type:    "Ready",
+				Status:  metav1.ConditionFalse,
+				Reason:  "InlineSecret",
+				Message: fmt.Sprintf("Inline secrets are not accepted, reference a Secret instead of setting %s", inlineSecretFields(secrets)),
+			})
+			user.Status.Phase = "Error"
+			_ = r.Status().Update(ctx, user)
+			return ctrl.Result{}, nil
+		case InlineSecretsConvert:
+			if err := r.convertInlineSecrets(ctx, user, secrets); err != nil {
+				log.Error(err, "Failed to move inline secrets to a Secret")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+	}
+
+	// A changed serverRef moves the user to the new server
+	moving, err := startMove(user)
+	if err != nil {
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "MoveInProgress",
+			Message: err.Error(),
+		})
+		user.Status.Phase = "Error"
+		_ = r.Status().Update(ctx, user)
+		return ctrl.Result{}, nil
+	}
+	if moving {
+		log.Info("Moving user", "from", user.Status.MovingFrom, "to", userServerKey(user))
+		r.Recorder.Eventf(user, corev1.EventTypeNormal, "Moving", "Moving user from %s to %s", user.Status.MovingFrom, userServerKey(user))
+	}
+
+	// Delete expired users once their grace period is over
+	expiresAt, err := userExpiration(user)
+	if err != nil {
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "ValidationError",
+			Message: err.Error(),
+		})
+		user.Status.Phase = "Error"
+		_ = r.Status().Update(ctx, user)
+		return ctrl.Result{}, nil
+	}
+	deleteAt := userDeletionTime(user, expiresAt)
+	if !deleteAt.IsZero() && !time.Now().Before(deleteAt) {
+		log.Info("Deleting expired user", "expiresAt", expiresAt)
+		r.Recorder.Eventf(user, corev1.EventTypeNormal, "Expired", "Deleting the SftpGoUser, the account expired at %s", expiresAt.UTC().Format(time.RFC3339))
+		if err := r.Delete(ctx, user); err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
 	}
 
 	// Fetch SftpGoServer
+	serverKey := userServerKey(user)
 	server := &sftpgov1alpha1.SftpGoServer{}
-	if err := r.Get(ctx, types.NamespacedName{Name: user.Spec.ServerRef.Name, Namespace: ns}, server); err != nil {
+	if err := r.Get(ctx, serverKey, server); err != nil {
 		if errors.IsNotFound(err) {
+			// The server watch requeues the user once the server is created
 			meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
 				Type:    "Ready",
 				Status:  metav1.ConditionFalse,
 				Reason:  "ServerNotFound",
-				Message: fmt.Sprintf("SftpGoServer %s not found in namespace %s", user.Spec.ServerRef.Name, ns),
+				Message: fmt.Sprintf("SftpGoServer %s not found in namespace %s", serverKey.Name, serverKey.Namespace),
 			})
 			user.Status.Phase = "Error"
 			_ = r.Status().Update(ctx, user)
@@ -105,16 +226,30 @@ func (r *SftpGoUserReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 		return ctrl.Result{}, err
 	}
+	// Servers in other namespaces must grant access to the user namespace,
+	// an account created before access was withdrawn is disabled
+	if err := checkServerAccess(ctx, r.Client, user, server); err != nil {
+		if disableErr := r.disableRevokedUser(ctx, user, server); disableErr != nil {
+			log.Error(disableErr, "Failed to disable the user on the server")
+			return ctrl.Result{}, disableErr
+		}
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "ReferenceNotPermitted",
+			Message: err.Error(),
+		})
+		user.Status.Phase = "Error"
+		r.Recorder.Event(user, corev1.EventTypeWarning, "ReferenceNotPermitted", err.Error())
+		_ = r.Status().Update(ctx, user)
+		return ctrl.Result{}, nil
+	}
 
 	// Build API URL (service is same name as server)
-	webPort := int32(8080)
-	if server.Spec.WebPort > 0 {
-		webPort = server.Spec.WebPort
-	}
-	baseURL := sftpgo.ServiceURL(server.Name, ns, webPort)
+	baseURL := serverAPIURL(server)
 
 	// Get admin credentials
-	username, password, err := r.getAdminCredentials(ctx, server)
+	username, password, err := adminCredentials(ctx, r.Client, server)
 	if err != nil {
 		log.Error(err, "Failed to get admin credentials")
 		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
@@ -141,20 +276,102 @@ func (r *SftpGoUserReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	client := sftpgo.NewClient(baseURL, username, password)
 
+	// Generate the requested credentials
+	if err := validateGeneratedCredentials(&user.Spec); err != nil {
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "ValidationError",
+			Message: err.Error(),
+		})
+		user.Status.Phase = "Error"
+		_ = r.Status().Update(ctx, user)
+		return ctrl.Result{}, nil
+	}
+	if err := validateHomeDirPolicy(user, server); err != nil {
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "ValidationError",
+			Message: err.Error(),
+		})
+		user.Status.Phase = "Error"
+		_ = r.Status().Update(ctx, user)
+		return ctrl.Result{}, nil
+	}
+	generated, err := r.reconcileGeneratedCredentials(ctx, user)
+	if err != nil {
+		log.Error(err, "Failed to generate credentials")
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "SecretError",
+			Message: err.Error(),
+		})
+		user.Status.Phase = "Error"
+		_ = r.Status().Update(ctx, user)
+		return ctrl.Result{}, err
+	}
+
 	// Resolve user password
-	userPassword, err := r.resolvePassword(ctx, user)
+	userPassword, err := r.resolvePassword(ctx, user, generated)
 	if err != nil {
+		log.Error(err, "Failed to resolve password")
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "SecretError",
+			Message: err.Error(),
+		})
+		user.Status.Phase = "Error"
+		_ = r.Status().Update(ctx, user)
 		return ctrl.Result{}, err
 	}
 
 	// Resolve public keys
-	publicKeys, err := r.resolvePublicKeys(ctx, user)
+	publicKeys, err := r.resolvePublicKeys(ctx, user, generated)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
+	// Resolve filesystem secrets
+	fsSecrets, err := r.resolveFilesystemSecrets(ctx, user)
+	if err != nil {
+		log.Error(err, "Failed to resolve filesystem secrets")
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "SecretError",
+			Message: err.Error(),
+		})
+		user.Status.Phase = "Error"
+		_ = r.Status().Update(ctx, user)
+		return ctrl.Result{}, err
+	}
+
 	// Build payload
 	payload := sftpgo.UserFromCR(&user.Spec, userPassword, publicKeys)
+	payload.Filesystem, err = sftpgo.FilesystemFromCR(user.Spec.Filesystem, fsSecrets)
+	if err == nil {
+		err = sftpgo.ApplyFilters(payload, &user.Spec)
+	}
+	if err == nil {
+		err = sftpgo.ApplyDirectoryPermissions(payload, &user.Spec)
+	}
+	if !expiresAt.IsZero() {
+		payload.ExpirationDate = expiresAt.UnixMilli()
+	}
+	if err != nil {
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "ValidationError",
+			Message: err.Error(),
+		})
+		user.Status.Phase = "Error"
+		_ = r.Status().Update(ctx, user)
+		return ctrl.Result{}, nil
+	}
 
 	// Create or update
 	existing, err := client.GetUser(user.Spec.Username)
@@ -171,12 +388,100 @@ func (r *SftpGoUserReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
+	if existing != nil && syncedWithoutUserID(user, serverKey.String()) {
+		log.Info("Recording the ID of a user synced before user IDs were kept", "userID", existing.ID)
+		user.Status.UserID = existing.ID
+	}
+	// The account on the target of a move is not ours until created or adopted
+	if existing != nil && user.Status.UserID == 0 {
+		policy, err := adoptionPolicy(user)
+		if err != nil {
+			meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+				Type:    "Ready",
+				Status:  metav1.ConditionFalse,
+				Reason:  "ValidationError",
+				Message: err.Error(),
+			})
+			user.Status.Phase = "Error"
+			_ = r.Status().Update(ctx, user)
+			return ctrl.Result{}, nil
+		}
+		switch policy {
+		case adoptionAdopt:
+			if err := r.checkAdoption(ctx, user, server); err != nil {
+				meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+					Type:    "Ready",
+					Status:  metav1.ConditionFalse,
+					Reason:  "AdoptionRefused",
+					Message: err.Error(),
+				})
+				user.Status.Phase = "Error"
+				r.Recorder.Event(user, corev1.EventTypeWarning, "AdoptionRefused", err.Error())
+				_ = r.Status().Update(ctx, user)
+				return ctrl.Result{}, nil
+			}
+			r.Recorder.Eventf(user, corev1.EventTypeNormal, "Adopted", "Adopted existing SFTPGO user %s", user.Spec.Username)
+		case adoptionIgnore:
+			meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+				Type:    "Ready",
+				Status:  metav1.ConditionFalse,
+				Reason:  "Ignored",
+				Message: fmt.Sprintf("User %s already exists in SFTPGO and is left unmanaged", user.Spec.Username),
+			})
+			user.Status.Phase = "Ignored"
+			_ = r.Status().Update(ctx, user)
+			return ctrl.Result{}, nil
+		default:
+			meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+				Type:   "Ready",
+				Status: metav1.ConditionFalse,
+				Reason: "AlreadyExists",
+				Message: fmt.Sprintf("User %s already exists in SFTPGO, set the %s annotation to %s to take it over",
+					user.Spec.Username, adoptionAnnotation, adoptionAdopt),
+			})
+			user.Status.Phase = "Error"
+			r.Recorder.Eventf(user, corev1.EventTypeWarning, "AlreadyExists", "User %s already exists in SFTPGO", user.Spec.Username)
+			_ = r.Status().Update(ctx, user)
+			return ctrl.Result{}, nil
+		}
+	}
+	if existing != nil {
+		payload.KeepUnmanaged(existing)
+	}
+
+	driftDetection := r.driftDetectionEnabled(user)
+	drifted := false
+	if driftDetection {
+		// Only a user synced at this generation can drift: other differences
+		// are pending spec changes
+		if user.Status.LastSynced != nil && user.Status.ObservedGeneration == user.Generation {
+			setDriftCondition(user, payload, existing)
+			if cond := meta.FindStatusCondition(user.Status.Conditions, "Drifted"); cond.Status == metav1.ConditionTrue {
+				log.Info("Reverting changes made outside the operator", "drift", cond.Message)
+				drifted = true
+			}
+		}
+	} else {
+		meta.RemoveStatusCondition(&user.Status.Conditions, "Drifted")
+	}
+
+	secretsHash := sftpgo.SecretsHash(payload, r.SecretsHashKey)
+	userID := 0
 	if existing != nil {
-		payload.ID = existing.ID
-		if userPassword == "" {
-			payload.Password = "" // Don't overwrite password if not provided
+		userID = existing.ID
+		diff := sftpgo.DiffUser(payload, existing)
+		secretsChanged := secretsHash != user.Status.SecretsHash
+		if len(diff) > 0 || secretsChanged {
+			_, err = client.UpdateUser(user.Spec.Username, payload)
+			if err == nil {
+				r.recordUpdate(user, diff, secretsChanged, drifted)
+				if reason := disconnectReason(user, payload, existing); reason != "" {
+					r.disconnectUser(ctx, client, user, reason)
+				}
+			}
+		} else {
+			log.V(1).Info("User is up to date in SFTPGO")
 		}
-		_, err = client.UpdateUser(user.Spec.Username, payload)
 	} else {
 		if userPassword == "" && len(publicKeys) == 0 {
 			meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
@@ -189,7 +494,60 @@ func (r *SftpGoUserReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			_ = r.Status().Update(ctx, user)
 			return ctrl.Result{}, nil
 		}
-		_, err = client.CreateUser(payload)
+		if policy := user.Spec.HomeDirPolicy; policy != nil && policy.Template != "" && !user.Status.HomeDirProvisioned {
+			if homeDirProvisionGaveUp(user) {
+				return ctrl.Result{}, nil
+			}
+			done, err := r.runHomeDirJob(ctx, user, r.homeDirJob(user, server, homeDirProvision))
+			if err != nil {
+				log.Error(err, "Failed to provision home directory")
+				reason, message := "HomeDirError", err.Error()
+				if _, failed := err.(*homeDirJobError); failed {
+					user.Status.HomeDirProvisionFailures++
+					if user.Status.HomeDirProvisionFailures >= maxHomeDirProvisionFailures {
+						reason = "HomeDirProvisionFailed"
+						message = fmt.Sprintf("Seeding the home directory from %s failed %d times, change the spec to retry: %v",
+							policy.Template, user.Status.HomeDirProvisionFailures, err)
+						r.Recorder.Event(user, corev1.EventTypeWarning, "HomeDirProvisionFailed", message)
+						err = nil
+					}
+				}
+				meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+					Type:               "Ready",
+					Status:             metav1.ConditionFalse,
+					Reason:             reason,
+					Message:            message,
+					ObservedGeneration: user.Generation,
+				})
+				user.Status.Phase = "Error"
+				_ = r.Status().Update(ctx, user)
+				return ctrl.Result{}, err
+			}
+			if !done {
+				meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+					Type:    "Ready",
+					Status:  metav1.ConditionFalse,
+					Reason:  "ProvisioningHomeDir",
+					Message: "Seeding the home directory from " + policy.Template,
+				})
+				user.Status.Phase = "Pending"
+				_ = r.Status().Update(ctx, user)
+				return ctrl.Result{RequeueAfter: homeDirJobPoll}, nil
+			}
+			user.Status.HomeDirProvisioned = true
+			user.Status.HomeDirProvisionFailures = 0
+			r.Recorder.Eventf(user, corev1.EventTypeNormal, "HomeDirProvisioned", "Seeded home directory from %s", policy.Template)
+		}
+		var created *sftpgo.UserPayload
+		created, err = client.CreateUser(payload)
+		if err == nil {
+			userID = created.ID
+			r.Recorder.Eventf(user, corev1.EventTypeNormal, "Created", "Created user %s in SFTPGO", user.Spec.Username)
+			if err := r.recordCreatedUser(ctx, user, userID, serverKey.String()); err != nil {
+				log.Error(err, "Failed to record the created user in status", "userID", userID)
+				return ctrl.Result{}, err
+			}
+		}
 	}
 	if err != nil {
 		log.Error(err, "Failed to create/update user in SFTPGO")
@@ -213,35 +571,99 @@ func (r *SftpGoUserReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	})
 	user.Status.Phase = "Synced"
 	user.Status.LastSynced = &now
-	if existing != nil {
-		user.Status.UserID = existing.ID
+	user.Status.ObservedGeneration = user.Generation
+	user.Status.SecretsHash = secretsHash
+	user.Status.ExpiresAt = nil
+	if !expiresAt.IsZero() {
+		user.Status.ExpiresAt = &metav1.Time{Time: expiresAt}
+	}
+	if userID != 0 {
+		user.Status.UserID = userID
+	}
+	user.Status.Username = user.Spec.Username
+	user.Status.Server = serverKey.String()
+	moveDone := true
+	if user.Status.MovingFrom != "" {
+		moveDone, err = r.finishMove(ctx, user, server)
+		if err != nil {
+			log.Error(err, "Failed to finish the move", "from", user.Status.MovingFrom)
+			meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+				Type:    "Moving",
+				Status:  metav1.ConditionTrue,
+				Reason:  "MoveError",
+				Message: err.Error(),
+			})
+			r.Recorder.Eventf(user, corev1.EventTypeWarning, "MoveFailed", "Move from %s failed: %v", user.Status.MovingFrom, err)
+			_ = r.Status().Update(ctx, user)
+			return ctrl.Result{}, err
+		}
+	}
+	if r.UsageInterval > 0 {
+		user.Status.Usage = r.pollUsage(ctx, client, user, payload, existing)
+	} else {
+		user.Status.Usage = nil
 	}
 	if err := r.Status().Update(ctx, user); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	var result ctrl.Result
+	if driftDetection {
+		result.RequeueAfter = r.ResyncInterval
+	}
+	requeueBefore(&result, nextCredentialsChange(&user.Status))
+	requeueBefore(&result, deleteAt)
+	if !moveDone && !moveFailed(user) {
+		requeueBefore(&result, now.Add(homeDirJobPoll))
+	}
+	return result, nil
 }
 
-func (r *SftpGoUserReconciler) getAdminCredentials(ctx context.Context, server *sftpgov1alpha1.SftpGoServer) (string, string, error) {
-	if server.Spec.AdminSecretRef == nil || server.Spec.AdminSecretRef.Name == "" {
-		return "", "", nil
-	}
+// driftDetectionEnabled reports whether the user is periodically resynced
+func (r *SftpGoUserReconciler) driftDetectionEnabled(user *sftpgov1alpha1.SftpGoUser) bool {
+	return r.ResyncInterval > 0 && user.Annotations[driftDetectionAnnotation] != "disabled"
+}
 
-	secret := &corev1.Secret{}
-	if err := r.Get(ctx, types.NamespacedName{
-		Name:      server.Spec.AdminSecretRef.Name,
-		Namespace: server.Namespace,
-	}, secret); err != nil {
-		return "", "", err
+// setDriftCondition compares the desired user with the one stored in SFTPGO
+// and records the diverging fields in the Drifted condition
+func setDriftCondition(user *sftpgov1alpha1.SftpGoUser, desired, actual *sftpgo.UserPayload) {
+	cond := metav1.Condition{
+		Type:   "Drifted",
+		Status: metav1.ConditionFalse,
+		Reason: "InSync",
 	}
+	if actual == nil {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "UserMissing"
+		cond.Message = "User was missing from SFTPGO and has been recreated"
+	} else if diff := sftpgo.DiffUser(desired, actual); len(diff) > 0 {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "DriftDetected"
+		cond.Message = "Reverted fields changed outside the operator: " + strings.Join(diff, ", ")
+	}
+	meta.SetStatusCondition(&user.Status.Conditions, cond)
+}
 
-	username := string(secret.Data["username"])
-	password := string(secret.Data["password"])
-	return username, password, nil
+// recordUpdate emits an Event describing why the user was updated in SFTPGO
+func (r *SftpGoUserReconciler) recordUpdate(user *sftpgov1alpha1.SftpGoUser, diff []string, secretsChanged, drifted bool) {
+	changes := diff
+	if secretsChanged {
+		changes = append(changes, "secrets")
+	}
+	switch {
+	case drifted:
+		r.Recorder.Eventf(user, corev1.EventTypeWarning, "DriftReverted", "Reverted fields changed outside the operator: %s", strings.Join(changes, ", "))
+	case len(diff) == 0:
+		r.Recorder.Event(user, corev1.EventTypeNormal, "SecretsUpdated", "Updated password or filesystem secrets in SFTPGO")
+	default:
+		r.Recorder.Eventf(user, corev1.EventTypeNormal, "Updated", "Updated fields in SFTPGO: %s", strings.Join(changes, ", "))
+	}
 }
 
-func (r *SftpGoUserReconciler) resolvePassword(ctx context.Context, user *sftpgov1alpha1.SftpGoUser) (string, error) {
+func (r *SftpGoUserReconciler) resolvePassword(ctx context.Context, user *sftpgov1alpha1.SftpGoUser, generated map[string][]byte) (string, error) {
+	if pw := generated[credentialsPasswordKey]; len(pw) > 0 {
+		return string(pw), nil
+	}
 	if user.Spec.Password != "" {
 		return user.Spec.Password, nil
 	}
@@ -255,14 +677,29 @@ func (r *SftpGoUserReconciler) resolvePassword(ctx context.Context, user *sftpgo
 		}
 		return string(secret.Data[user.Spec.PasswordSecretRef.Key]), nil
 	}
+	if ref := user.Spec.PasswordHashSecretRef; ref != nil {
+		// SFTPGO stores recognized hashes as they are, anything else would be
+		// hashed again as a plain password
+		hash, err := r.secretValue(ctx, user.Namespace, ref)
+		if err != nil {
+			return "", err
+		}
+		if err := sftpgo.ValidatePasswordHash(hash); err != nil {
+			return "", fmt.Errorf("key %s of secret %s: %w", ref.Key, ref.Name, err)
+		}
+		return hash, nil
+	}
 	return "", nil
 }
 
-func (r *SftpGoUserReconciler) resolvePublicKeys(ctx context.Context, user *sftpgov1alpha1.SftpGoUser) ([]string, error) {
+// resolvePublicKeys returns the public keys of the spec, or of the
+// PublicKeysSecretRef, followed by the generated public key and the previous
+// one during a rotation overlap
+func (r *SftpGoUserReconciler) resolvePublicKeys(ctx context.Context, user *sftpgov1alpha1.SftpGoUser, generated map[string][]byte) ([]string, error) {
+	var out []string
 	if len(user.Spec.PublicKeys) > 0 {
-		return user.Spec.PublicKeys, nil
-	}
-	if user.Spec.PublicKeysSecretRef != nil {
+		out = append(out, user.Spec.PublicKeys...)
+	} else if user.Spec.PublicKeysSecretRef != nil {
 		secret := &corev1.Secret{}
 		if err := r.Get(ctx, types.NamespacedName{
 			Name:      user.Spec.PublicKeysSecretRef.Name,
@@ -272,49 +709,148 @@ func (r *SftpGoUserReconciler) resolvePublicKeys(ctx context.Context, user *sftp
 		}
 		raw := string(secret.Data[user.Spec.PublicKeysSecretRef.Key])
 		keys := strings.Split(raw, "\n")
-		var out []string
 		for _, k := range keys {
 			k = strings.TrimSpace(k)
 			if k != "" && !strings.HasPrefix(k, "#") {
 				out = append(out, k)
 			}
 		}
+	}
+	for _, k := range []string{credentialsPublicKeyKey, credentialsPreviousPublicKeyKey} {
+		if key := generated[k]; len(key) > 0 {
+			out = append(out, string(key))
+		}
+	}
+	return out, nil
+}
+
+// resolveFilesystemSecrets reads every SecretRef of the user's filesystem config
+func (r *SftpGoUserReconciler) resolveFilesystemSecrets(ctx context.Context, user *sftpgov1alpha1.SftpGoUser) (sftpgo.FilesystemSecrets, error) {
+	var out sftpgo.FilesystemSecrets
+	fs := user.Spec.Filesystem
+	if fs == nil {
 		return out, nil
 	}
-	return nil, nil
+	refs := map[*string]*sftpgov1alpha1.SecretRef{}
+	if fs.S3 != nil {
+		refs[&out.S3AccessSecret] = fs.S3.AccessSecret
+	}
+	if fs.GCS != nil {
+		refs[&out.GCSCredentials] = fs.GCS.Credentials
+	}
+	if fs.Azure != nil {
+		refs[&out.AzureAccountKey] = fs.Azure.AccountKey
+		refs[&out.AzureSASURL] = fs.Azure.SASURLSecretRef
+	}
+	if fs.SFTP != nil {
+		refs[&out.SFTPPassword] = fs.SFTP.Password
+		refs[&out.SFTPPrivateKey] = fs.SFTP.PrivateKey
+	}
+	if fs.Crypt != nil {
+		refs[&out.CryptPassphrase] = fs.Crypt.Passphrase
+	}
+	for dst, ref := range refs {
+		if ref == nil {
+			continue
+		}
+		value, err := r.secretValue(ctx, user.Namespace, ref)
+		if err != nil {
+			return out, err
+		}
+		*dst = value
+	}
+	return out, nil
+}
+
+// secretValue reads a key of a Secret, failing if the key is missing
+func (r *SftpGoUserReconciler) secretValue(ctx context.Context, namespace string, ref *sftpgov1alpha1.SecretRef) (string, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, secret); err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s", ref.Key, ref.Name)
+	}
+	return string(value), nil
 }
 
 func (r *SftpGoUserReconciler) deleteUserFromSFTPGO(ctx context.Context, user *sftpgov1alpha1.SftpGoUser) error {
-	ns := user.Spec.ServerRef.Namespace
-	if ns == "" {
-		ns = user.Namespace
+	for _, key := range accountServerKeys(user) {
+		server := &sftpgov1alpha1.SftpGoServer{}
+		if err := r.Get(ctx, key, server); err != nil {
+			if errors.IsNotFound(err) {
+				continue // Server gone, nothing to delete
+			}
+			return err
+		}
+		if err := r.deleteUserFrom(ctx, user, server, "deleted"); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	server := &sftpgov1alpha1.SftpGoServer{}
-	if err := r.Get(ctx, types.NamespacedName{Name: user.Spec.ServerRef.Name, Namespace: ns}, server); err != nil {
-		if errors.IsNotFound(err) {
-			return nil // Server gone, nothing to delete
+// accountServerKeys returns the servers holding an account the operator
+// created or adopted for the user: an unfinished move may leave one on the
+// previous server too, and none yet on the target
+func accountServerKeys(user *sftpgov1alpha1.SftpGoUser) []types.NamespacedName {
+	var keys []types.NamespacedName
+	if user.Status.UserID != 0 || syncedWithoutUserID(user, syncedServerKey(user).String()) {
+		key := userServerKey(user)
+		if user.Status.Server != "" {
+			key = parseServerKey(user.Status.Server)
 		}
-		return err
+		keys = append(keys, key)
 	}
+	if user.Status.MovingFrom != "" && user.Status.MovingFromUserID != 0 {
+		keys = append(keys, parseServerKey(user.Status.MovingFrom))
+	}
+	return keys
+}
 
-	username, password, err := r.getAdminCredentials(ctx, server)
+// deleteUserFrom deletes the user from one SFTPGO server and closes its
+// sessions there. The account was created or adopted by the operator, so it
+// is deleted even when the server no longer allows the user namespace.
+func (r *SftpGoUserReconciler) deleteUserFrom(ctx context.Context, user *sftpgov1alpha1.SftpGoUser,
+	server *sftpgov1alpha1.SftpGoServer, reason string) error {
+	username, password, err := adminCredentials(ctx, r.Client, server)
 	if err != nil || username == "" || password == "" {
 		return nil // Can't authenticate, skip delete
 	}
 
-	webPort := int32(8080)
-	if server.Spec.WebPort > 0 {
-		webPort = server.Spec.WebPort
+	client := sftpgo.NewClient(serverAPIURL(server), username, password)
+	if err := client.DeleteUser(syncedUsername(user)); err != nil {
+		return err
 	}
-	client := sftpgo.NewClient(sftpgo.ServiceURL(server.Name, ns, webPort), username, password)
-	return client.DeleteUser(user.Spec.Username)
+	// Deleting the account does not end its sessions
+	r.disconnectUser(ctx, client, user, reason)
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *SftpGoUserReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := setupUserIndexes(context.Background(), mgr); err != nil {
+		return err
+	}
+	// Usage is polled apart from the reconcile, which would sync the users
+	if r.UsageInterval > 0 {
+		if err := mgr.Add(manager.RunnableFunc(r.pollServersUsage)); err != nil {
+			return err
+		}
+	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&sftpgov1alpha1.SftpGoUser{}).
+		// Status updates must not trigger a resync
+		For(&sftpgov1alpha1.SftpGoUser{}, builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Owns(&batchv1.Job{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.usersForSecret)).
+		// Server spec changes (admin Secret, web port) and creation, not its status
+		Watches(&sftpgov1alpha1.SftpGoServer{}, handler.EnqueueRequestsFromMapFunc(r.usersForServer),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Namespace labels decide which servers the users may reference
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.usersForNamespace),
+			builder.WithPredicates(predicate.LabelChangedPredicate{})).
 		Named("sftpgouser").
 		Complete(r)
 }
@@ -0,0 +1,306 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+const (
+	// rotateCredentialsAnnotation requests new generated credentials, it is
+	// removed once they are generated
+	rotateCredentialsAnnotation = "sftpgo.sftpgo.io/rotate-credentials"
+	// credentialsGeneratedAtAnnotation records on the credentials Secret when
+	// its credentials were generated
+	credentialsGeneratedAtAnnotation = "sftpgo.sftpgo.io/generated-at"
+
+	// Keys of the generated credentials Secret
+	credentialsUsernameKey   = "username"
+	credentialsPasswordKey   = "password"
+	credentialsPrivateKeyKey = corev1.SSHAuthPrivateKey
+	credentialsPublicKeyKey  = "ssh-publickey"
+	// credentialsPreviousPublicKeyKey holds the public key replaced by the
+	// last rotation during the overlap window
+	credentialsPreviousPublicKeyKey = "ssh-publickey-previous"
+
+	defaultPasswordLength = 24
+)
+
+// Password alphabet, without quotes, spaces and shell metacharacters so that
+// passwords can be pasted anywhere
+const (
+	passwordLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	passwordDigits  = "0123456789"
+	passwordSymbols = "!#%+-.:=?@^_~"
+)
+
+// credentialsSecretName returns the name of the generated credentials Secret
+func credentialsSecretName(user *sftpgov1alpha1.SftpGoUser) string {
+	if name := user.Spec.GeneratedCredentials.SecretName; name != "" {
+		return name
+	}
+	return user.Name + "-credentials"
+}
+
+// validateGeneratedCredentials rejects generated credentials conflicting with
+// the ones set in the spec
+func validateGeneratedCredentials(spec *sftpgov1alpha1.SftpGoUserSpec) error {
+	gen := spec.GeneratedCredentials
+	if gen == nil {
+		return nil
+	}
+	if gen.Password != nil && (spec.Password != "" || spec.PasswordSecretRef != nil || spec.PasswordHashSecretRef != nil) {
+		return fmt.Errorf("generatedCredentials.password cannot be used with password, passwordSecretRef or passwordHashSecretRef")
+	}
+	if gen.Password == nil && !gen.SSHKey {
+		return fmt.Errorf("generatedCredentials requires password or sshKey")
+	}
+	if rot := gen.Rotation; rot != nil {
+		if rot.Interval.Duration <= 0 {
+			return fmt.Errorf("generatedCredentials.rotation.interval must be positive")
+		}
+		if rot.Overlap.Duration < 0 || rot.Overlap.Duration >= rot.Interval.Duration {
+			return fmt.Errorf("generatedCredentials.rotation.overlap must be shorter than the interval")
+		}
+	}
+	return nil
+}
+
+// reconcileGeneratedCredentials makes sure the credentials Secret holds the
+// requested credentials, generating the missing ones and all of them when a
+// rotation is due or requested. During the rotation overlap window the
+// previous public key is kept in the Secret so that SFTPGO accepts both keys.
+// It returns the Secret data, nil when the user has no generated credentials.
+func (r *SftpGoUserReconciler) reconcileGeneratedCredentials(ctx context.Context, user *sftpgov1alpha1.SftpGoUser) (map[string][]byte, error) {
+	gen := user.Spec.GeneratedCredentials
+	if gen == nil {
+		user.Status.CredentialsGeneratedAt = nil
+		user.Status.NextCredentialsRotation = nil
+		user.Status.PreviousPublicKeyExpiresAt = nil
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	secret.Name = credentialsSecretName(user)
+	secret.Namespace = user.Namespace
+	if err := r.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+	} else if !metav1.IsControlledBy(secret, user) {
+		return nil, fmt.Errorf("secret %s already exists and is not owned by the SftpGoUser", secret.Name)
+	}
+
+	var generatedAt time.Time
+	if t, err := time.Parse(time.RFC3339, secret.Annotations[credentialsGeneratedAtAnnotation]); err == nil {
+		generatedAt = t
+	}
+	var interval, overlap time.Duration
+	if gen.Rotation != nil {
+		interval = gen.Rotation.Interval.Duration
+		overlap = gen.Rotation.Overlap.Duration
+	}
+	now := time.Now()
+	requested := user.Annotations[rotateCredentialsAnnotation] != ""
+	due := interval > 0 && !generatedAt.IsZero() && !now.Before(generatedAt.Add(interval))
+	rotate := requested || due
+
+	data, generated, err := generateCredentials(gen, user.Spec.Username, secret.Data, rotate)
+	if err != nil {
+		return nil, err
+	}
+	// Keep accepting the previous public key during the overlap window
+	if gen.SSHKey && overlap > 0 {
+		if rotate {
+			if pub := secret.Data[credentialsPublicKeyKey]; len(pub) > 0 {
+				data[credentialsPreviousPublicKeyKey] = pub
+			}
+		} else if prev := secret.Data[credentialsPreviousPublicKeyKey]; len(prev) > 0 && now.Before(generatedAt.Add(overlap)) {
+			data[credentialsPreviousPublicKeyKey] = prev
+		}
+	}
+
+	if generated || generatedAt.IsZero() {
+		generatedAt = now.Truncate(time.Second)
+	}
+	hadPrevious := len(secret.Data[credentialsPreviousPublicKeyKey]) > 0
+	if !reflect.DeepEqual(data, secret.Data) || secret.Annotations[credentialsGeneratedAtAnnotation] == "" {
+		if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+			secret.Type = corev1.SecretTypeOpaque
+			secret.Data = data
+			if secret.Annotations == nil {
+				secret.Annotations = map[string]string{}
+			}
+			secret.Annotations[credentialsGeneratedAtAnnotation] = generatedAt.UTC().Format(time.RFC3339)
+			return controllerutil.SetControllerReference(user, secret, r.Scheme)
+		}); err != nil {
+			return nil, err
+		}
+		switch {
+		case generated && rotate && len(data[credentialsPreviousPublicKeyKey]) > 0:
+			r.Recorder.Eventf(user, corev1.EventTypeNormal, "CredentialsRotated",
+				"Rotated the credentials in Secret %s, the previous public key is accepted until %s",
+				secret.Name, generatedAt.Add(overlap).UTC().Format(time.RFC3339))
+		case generated && rotate:
+			r.Recorder.Eventf(user, corev1.EventTypeNormal, "CredentialsRotated", "Rotated the credentials in Secret %s", secret.Name)
+		case generated:
+			r.Recorder.Eventf(user, corev1.EventTypeNormal, "CredentialsGenerated", "Generated credentials in Secret %s", secret.Name)
+		case hadPrevious && len(data[credentialsPreviousPublicKeyKey]) == 0:
+			r.Recorder.Event(user, corev1.EventTypeNormal, "PreviousKeyRemoved", "Removed the previous public key at the end of the rotation overlap")
+		}
+	}
+
+	// Remove the rotation request with a metadata patch on a copy, updating
+	// the user itself would reset the status set earlier in the reconcile
+	if requested {
+		patched := user.DeepCopy()
+		delete(patched.Annotations, rotateCredentialsAnnotation)
+		if err := r.Patch(ctx, patched, client.MergeFrom(user)); err != nil {
+			return nil, err
+		}
+		delete(user.Annotations, rotateCredentialsAnnotation)
+		user.ResourceVersion = patched.ResourceVersion
+	}
+	user.Status.CredentialsGeneratedAt = &metav1.Time{Time: generatedAt}
+	user.Status.NextCredentialsRotation = nil
+	if interval > 0 {
+		user.Status.NextCredentialsRotation = &metav1.Time{Time: generatedAt.Add(interval)}
+	}
+	user.Status.PreviousPublicKeyExpiresAt = nil
+	if len(data[credentialsPreviousPublicKeyKey]) > 0 {
+		user.Status.PreviousPublicKeyExpiresAt = &metav1.Time{Time: generatedAt.Add(overlap)}
+	}
+	return data, nil
+}
+
+// nextCredentialsChange returns when the generated credentials of a user
+// change next, either rotated or losing their previous public key, zero when
+// no change is scheduled
+func nextCredentialsChange(status *sftpgov1alpha1.SftpGoUserStatus) time.Time {
+	var next time.Time
+	for _, t := range []*metav1.Time{status.NextCredentialsRotation, status.PreviousPublicKeyExpiresAt} {
+		if t != nil && (next.IsZero() || t.Time.Before(next)) {
+			next = t.Time
+		}
+	}
+	return next
+}
+
+// generateCredentials returns the credentials Secret data: the current data
+// with the missing credentials generated, or all of them when rotating, and
+// whether any credential was generated. Credentials no longer requested are
+// removed.
+func generateCredentials(gen *sftpgov1alpha1.GeneratedCredentials, username string, current map[string][]byte, rotate bool) (map[string][]byte, bool, error) {
+	data := map[string][]byte{credentialsUsernameKey: []byte(username)}
+	generated := false
+	if gen.Password != nil {
+		if pw := current[credentialsPasswordKey]; len(pw) > 0 && !rotate {
+			data[credentialsPasswordKey] = pw
+		} else {
+			pw, err := generatePassword(gen.Password)
+			if err != nil {
+				return nil, false, err
+			}
+			data[credentialsPasswordKey] = []byte(pw)
+			generated = true
+		}
+	}
+	if gen.SSHKey {
+		priv, pub := current[credentialsPrivateKeyKey], current[credentialsPublicKeyKey]
+		if len(priv) == 0 || len(pub) == 0 || rotate {
+			var err error
+			if priv, pub, err = generateSSHKey(username); err != nil {
+				return nil, false, err
+			}
+			generated = true
+		}
+		data[credentialsPrivateKeyKey] = priv
+		data[credentialsPublicKeyKey] = pub
+	}
+	return data, generated, nil
+}
+
+// generatePassword returns a random password with at least a letter, a digit
+// and, when enabled, a symbol
+func generatePassword(policy *sftpgov1alpha1.PasswordPolicy) (string, error) {
+	length := policy.Length
+	if length <= 0 {
+		length = defaultPasswordLength
+	}
+	classes := []string{passwordLetters, passwordDigits}
+	if policy.Symbols {
+		classes = append(classes, passwordSymbols)
+	}
+	alphabet := strings.Join(classes, "")
+	max := big.NewInt(int64(len(alphabet)))
+	for {
+		pw := make([]byte, length)
+		for i := range pw {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return "", err
+			}
+			pw[i] = alphabet[n.Int64()]
+		}
+		complete := true
+		for _, class := range classes {
+			if !strings.ContainsAny(string(pw), class) {
+				complete = false
+			}
+		}
+		if complete {
+			return string(pw), nil
+		}
+	}
+}
+
+// generateSSHKey returns a new ed25519 keypair: the private key in the
+// OpenSSH format and the public key in the authorized_keys format
+func generateSSHKey(comment string) ([]byte, []byte, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, err := ssh.MarshalPrivateKey(priv, comment)
+	if err != nil {
+		return nil, nil, err
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return nil, nil, err
+	}
+	// MarshalAuthorizedKey ends the line with a newline and has no comment
+	authorizedKey := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(sshPub)), "\n") + " " + comment
+	return pem.EncodeToMemory(block), []byte(authorizedKey), nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+// userExpiration returns when the account expires, zero when it never does
+func userExpiration(user *sftpgov1alpha1.SftpGoUser) (time.Time, error) {
+	spec := &user.Spec
+	switch {
+	case spec.ExpiresAt != nil && spec.TTL != nil:
+		return time.Time{}, fmt.Errorf("expiresAt and ttl cannot be used together")
+	case spec.ExpiresAt != nil:
+		return spec.ExpiresAt.Time, nil
+	case spec.TTL != nil:
+		if spec.TTL.Duration <= 0 {
+			return time.Time{}, fmt.Errorf("ttl must be positive")
+		}
+		return user.CreationTimestamp.Add(spec.TTL.Duration), nil
+	}
+	return time.Time{}, nil
+}
+
+// userDeletionTime returns when an expired user is deleted, zero when it is kept
+func userDeletionTime(user *sftpgov1alpha1.SftpGoUser, expiresAt time.Time) time.Time {
+	if expiresAt.IsZero() || user.Spec.DeleteAfterExpiry == nil {
+		return time.Time{}
+	}
+	return expiresAt.Add(user.Spec.DeleteAfterExpiry.Duration)
+}
+
+// requeueBefore shortens the requeue of a result so that the user is
+// reconciled again at t
+func requeueBefore(result *ctrl.Result, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	if wait := max(time.Until(t), time.Second); result.RequeueAfter == 0 || wait < result.RequeueAfter {
+		result.RequeueAfter = wait
+	}
+}
@@ -0,0 +1,435 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+// Home directory actions run by Jobs
+const (
+	homeDirProvision = "provision"
+	homeDirDelete    = "delete"
+	homeDirArchive   = "archive"
+	homeDirMigrate   = "migrate"
+
+	// homeDirSourceMount is where migrate Jobs mount the previous server data volume
+	homeDirSourceMount = "/mnt/source"
+
+	// Labels of the home directory Jobs naming their user, which may be in
+	// another namespace than the Job
+	homeDirUserLabel          = "sftpgo.sftpgo.io/user"
+	homeDirUserNamespaceLabel = "sftpgo.sftpgo.io/user-namespace"
+
+	// DefaultHomeDirJobImage is the image of the home directory Jobs. The
+	// SFTPGO images have no shell.
+	DefaultHomeDirJobImage = "busybox:1.37"
+
+	// sftpgoUID is the user and group SFTPGO runs as, the Jobs use it so
+	// the files they create stay writable by the server
+	sftpgoUID = 1000
+
+	// maxHomeDirCleanupFailures is how many cleanup Jobs of a deleted user
+	// can fail before its home directory is kept and the user released
+	maxHomeDirCleanupFailures = 3
+	// maxHomeDirProvisionFailures is how many provisioning Jobs of a user can
+	// fail before provisioning waits for a spec change
+	maxHomeDirProvisionFailures = 3
+)
+
+// homeDirJobPoll is how often unfinished home directory Jobs are checked,
+// Jobs in the namespace of the user also trigger a reconcile when done
+const homeDirJobPoll = 10 * time.Second
+
+// homeDirJobTimeout is how long a home directory Job may run, or wait for its
+// volumes, before it fails
+const homeDirJobTimeout = time.Hour
+
+// homeDirScripts are the shell scripts of the home directory Jobs. Paths are
+// passed in the environment.
+var homeDirScripts = map[string]string{
+	homeDirProvision: `if [ -z "$(ls -A "$HOME_DIR" 2>/dev/null)" ]; then mkdir -p "$HOME_DIR" && cp -a "$TEMPLATE_DIR/." "$HOME_DIR/"; fi`,
+	homeDirDelete:    `rm -rf -- "$HOME_DIR"`,
+	homeDirArchive: `if [ -d "$HOME_DIR" ]; then mkdir -p "$ARCHIVE_DIR" && ` +
+		`tar -czf "$ARCHIVE_DIR/$ARCHIVE_NAME" -C "$HOME_DIR" . && rm -rf -- "$HOME_DIR"; fi`,
+	homeDirMigrate: `if [ -d "$SOURCE_DIR" ]; then mkdir -p "$HOME_DIR" && cp -a "$SOURCE_DIR/." "$HOME_DIR/"; fi`,
+}
+
+// homeDirOnDelete returns the home directory deletion policy of a user
+func homeDirOnDelete(user *sftpgov1alpha1.SftpGoUser) string {
+	if p := user.Spec.HomeDirPolicy; p != nil && p.OnDelete != "" {
+		return p.OnDelete
+	}
+	return "Keep"
+}
+
+// validateHomeDirPolicy checks that the Jobs of the home directory policy can
+// reach the home directory: a local filesystem stored in the server data
+// volume, with the other paths of the policy in the same volume
+func validateHomeDirPolicy(user *sftpgov1alpha1.SftpGoUser, server *sftpgov1alpha1.SftpGoServer) error {
+	policy := user.Spec.HomeDirPolicy
+	if policy == nil || (policy.Template == "" && homeDirOnDelete(user) == "Keep" && !policy.MigrateOnMove) {
+		return nil
+	}
+	if fs := user.Spec.Filesystem; fs != nil {
+		switch fs.Provider {
+		case "", "osfs", "crypt", "encrypted":
+		default:
+			return fmt.Errorf("homeDirPolicy requires a local filesystem, not %s", fs.Provider)
+		}
+	}
+	if server.Spec.DataVolume == nil {
+		return fmt.Errorf("homeDirPolicy requires the SftpGoServer dataVolume")
+	}
+	mountPath := serverDataMountPath(server)
+	home := path.Clean(user.Spec.HomeDir)
+	if !isSubPath(mountPath, home) {
+		return fmt.Errorf("homeDir %s is not in the server data volume %s", user.Spec.HomeDir, mountPath)
+	}
+	if policy.Template != "" && !isSubPath(mountPath, path.Clean(policy.Template)) {
+		return fmt.Errorf("homeDirPolicy.template %s is not in the server data volume %s", policy.Template, mountPath)
+	}
+	if homeDirOnDelete(user) == "Archive" {
+		archive := path.Clean(policy.ArchivePath)
+		if policy.ArchivePath == "" || !isSubPath(mountPath, archive) {
+			return fmt.Errorf("homeDirPolicy.archivePath must be a directory of the server data volume %s", mountPath)
+		}
+		if archive == home || isSubPath(home, archive) {
+			return fmt.Errorf("homeDirPolicy.archivePath cannot be in the home directory")
+		}
+	}
+	return nil
+}
+
+// serverDataMountPath returns where the server mounts its data volume
+func serverDataMountPath(server *sftpgov1alpha1.SftpGoServer) string {
+	if dv := server.Spec.DataVolume; dv != nil && dv.MountPath != "" {
+		return path.Clean(dv.MountPath)
+	}
+	return "/srv/sftpgo"
+}
+
+// isSubPath reports whether p is strictly inside the absolute directory dir
+func isSubPath(dir, p string) bool {
+	return path.IsAbs(p) && strings.HasPrefix(p, strings.TrimSuffix(dir, "/")+"/")
+}
+
+// homeDirJobError is returned when a home directory Job failed
+type homeDirJobError struct {
+	name string
+}
+
+func (e *homeDirJobError) Error() string {
+	return fmt.Sprintf("home directory job %s failed", e.name)
+}
+
+// homeDirJobImage returns the image of the home directory Jobs
+func (r *SftpGoUserReconciler) homeDirJobImage() string {
+	if r.HomeDirJobImage != "" {
+		return r.HomeDirJobImage
+	}
+	return DefaultHomeDirJobImage
+}
+
+// homeDirJob returns the Job running a home directory action against the
+// server data volume. The volume is usually ReadWriteOnce, so the Job runs
+// on the node of the server pods.
+func (r *SftpGoUserReconciler) homeDirJob(user *sftpgov1alpha1.SftpGoUser, server *sftpgov1alpha1.SftpGoServer, action string) *batchv1.Job {
+	mountPath := serverDataMountPath(server)
+	env := []corev1.EnvVar{{Name: "HOME_DIR", Value: path.Clean(user.Spec.HomeDir)}}
+	if policy := user.Spec.HomeDirPolicy; policy != nil {
+		switch action {
+		case homeDirProvision:
+			env = append(env, corev1.EnvVar{Name: "TEMPLATE_DIR", Value: path.Clean(policy.Template)})
+		case homeDirArchive:
+			name := user.Spec.Username + ".tar.gz"
+			if user.DeletionTimestamp != nil {
+				name = fmt.Sprintf("%s-%s.tar.gz", user.Spec.Username, user.DeletionTimestamp.UTC().Format("20060102T150405Z"))
+			}
+			env = append(env,
+				corev1.EnvVar{Name: "ARCHIVE_DIR", Value: path.Clean(policy.ArchivePath)},
+				corev1.EnvVar{Name: "ARCHIVE_NAME", Value: name})
+		}
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      homeDirJobName(user, action),
+			Namespace: server.Namespace,
+			Labels: map[string]string{
+				"app":                     "sftpgo",
+				homeDirUserLabel:          user.Name,
+				homeDirUserNamespaceLabel: user.Namespace,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          ptr.To(int32(3)),
+			ActiveDeadlineSeconds: ptr.To(int64(homeDirJobTimeout.Seconds())),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Affinity: &corev1.Affinity{
+						PodAffinity: &corev1.PodAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+								LabelSelector: &metav1.LabelSelector{MatchLabels: labelsForServer(server)},
+								TopologyKey:   corev1.LabelHostname,
+							}},
+						},
+					},
+					Containers: []corev1.Container{{
+						Name:         "home-" + action,
+						Image:        r.homeDirJobImage(),
+						Command:      []string{"/bin/sh", "-c", homeDirScripts[action]},
+						Env:          env,
+						VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: mountPath}},
+						SecurityContext: &corev1.SecurityContext{
+							RunAsUser:  ptr.To(int64(sftpgoUID)),
+							RunAsGroup: ptr.To(int64(sftpgoUID)),
+						},
+					}},
+					Volumes: []corev1.Volume{{
+						Name: "data",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: server.Name + "-data"},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// homeDirJobName returns the name of a home directory Job, short enough for
+// the job-name label. The Jobs run in the server namespace, shared by users
+// of several namespaces, so the name includes a hash of the user namespace
+// and name.
+func homeDirJobName(user *sftpgov1alpha1.SftpGoUser, action string) string {
+	sum := sha256.Sum256([]byte(user.Namespace + "/" + user.Name))
+	suffix := "-" + hex.EncodeToString(sum[:4]) + "-home-" + action
+	name := user.Name
+	if max := 63 - len(suffix); len(name) > max {
+		name = strings.TrimRight(name[:max], "-.")
+	}
+	return name + suffix
+}
+
+// isHomeDirJobOf reports whether a home directory Job was created for the user
+func isHomeDirJobOf(job *batchv1.Job, user *sftpgov1alpha1.SftpGoUser) bool {
+	return job.Labels[homeDirUserLabel] == user.Name && job.Labels[homeDirUserNamespaceLabel] == user.Namespace
+}
+
+// migrateHomeDirJob returns the Job copying the home directory from the data
+// volume of the previous server of a moved user
+func (r *SftpGoUserReconciler) migrateHomeDirJob(user *sftpgov1alpha1.SftpGoUser, source, target *sftpgov1alpha1.SftpGoServer) *batchv1.Job {
+	job := r.homeDirJob(user, target, homeDirMigrate)
+	pod := &job.Spec.Template.Spec
+	rel := strings.TrimPrefix(path.Clean(user.Spec.HomeDir), serverDataMountPath(source))
+	pod.Containers[0].Env = append(pod.Containers[0].Env, corev1.EnvVar{Name: "SOURCE_DIR", Value: homeDirSourceMount + rel})
+	pod.Containers[0].VolumeMounts = append(pod.Containers[0].VolumeMounts,
+		corev1.VolumeMount{Name: "source", MountPath: homeDirSourceMount, ReadOnly: true})
+	pod.Volumes = append(pod.Volumes, corev1.Volume{
+		Name: "source",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: source.Name + "-data", ReadOnly: true},
+		},
+	})
+	return job
+}
+
+// validateMigration checks that a migrate Job can mount the data volumes of
+// both servers
+func validateMigration(user *sftpgov1alpha1.SftpGoUser, source, target *sftpgov1alpha1.SftpGoServer) error {
+	if source.Namespace != target.Namespace {
+		return fmt.Errorf("homeDirPolicy.migrateOnMove requires both servers in the same namespace")
+	}
+	if source.Spec.DataVolume == nil {
+		return fmt.Errorf("homeDirPolicy.migrateOnMove requires a dataVolume on %s/%s", source.Namespace, source.Name)
+	}
+	if mountPath := serverDataMountPath(source); !isSubPath(mountPath, path.Clean(user.Spec.HomeDir)) {
+		return fmt.Errorf("homeDir %s is not in the data volume %s of %s/%s", user.Spec.HomeDir, mountPath, source.Namespace, source.Name)
+	}
+	return nil
+}
+
+// checkMigrationVolumes returns an error when the migrate Job, which runs on
+// the node of the target server pods, cannot mount the source data volume: a
+// ReadWriteOnce volume only attaches to one node, so the source server pods
+// must be stopped or run on that node too
+func (r *SftpGoUserReconciler) checkMigrationVolumes(ctx context.Context, source, target *sftpgov1alpha1.SftpGoServer) error {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Name: source.Name + "-data", Namespace: source.Namespace}, pvc); err != nil {
+		return err
+	}
+	if slices.Contains(pvc.Spec.AccessModes, corev1.ReadWriteMany) || slices.Contains(pvc.Spec.AccessModes, corev1.ReadOnlyMany) {
+		return nil
+	}
+	targetNodes, err := r.serverNodes(ctx, target)
+	if err != nil {
+		return err
+	}
+	if len(targetNodes) != 1 {
+		return fmt.Errorf("homeDirPolicy.migrateOnMove requires the pods of %s/%s running on one node, found %d",
+			target.Namespace, target.Name, len(targetNodes))
+	}
+	sourceNodes, err := r.serverNodes(ctx, source)
+	if err != nil {
+		return err
+	}
+	for _, node := range sourceNodes {
+		if node != targetNodes[0] {
+			return fmt.Errorf("the ReadWriteOnce data volume of %s/%s is used on node %s, not on node %s of %s/%s: "+
+				"scale %s/%s down or use a ReadWriteMany volume to migrate the home directory",
+				source.Namespace, source.Name, node, targetNodes[0], target.Namespace, target.Name, source.Namespace, source.Name)
+		}
+	}
+	return nil
+}
+
+// serverNodes returns the nodes the running pods of a server are scheduled on
+func (r *SftpGoUserReconciler) serverNodes(ctx context.Context, server *sftpgov1alpha1.SftpGoServer) ([]string, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(server.Namespace), client.MatchingLabels(labelsForServer(server))); err != nil {
+		return nil, err
+	}
+	var nodes []string
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if !slices.Contains(nodes, pod.Spec.NodeName) {
+			nodes = append(nodes, pod.Spec.NodeName)
+		}
+	}
+	return nodes, nil
+}
+
+// runHomeDirJob creates a home directory Job and reports whether it
+// completed, deleting it once it did. A failed Job is deleted too so the
+// action is retried.
+func (r *SftpGoUserReconciler) runHomeDirJob(ctx context.Context, user *sftpgov1alpha1.SftpGoUser, desired *batchv1.Job) (bool, error) {
+	job := &batchv1.Job{}
+	err := r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, job)
+	if errors.IsNotFound(err) {
+		// Jobs of users in other namespaces are cleaned up with the user
+		if desired.Namespace == user.Namespace {
+			if err := controllerutil.SetControllerReference(user, desired, r.Scheme); err != nil {
+				return false, err
+			}
+		}
+		return false, r.Create(ctx, desired)
+	}
+	if err != nil {
+		return false, err
+	}
+	if !isHomeDirJobOf(job, user) {
+		return false, fmt.Errorf("job %s/%s exists and was not created for the SftpGoUser", job.Namespace, job.Name)
+	}
+
+	failed := false
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			failed = true
+		}
+	}
+	if job.Status.Succeeded == 0 && !failed {
+		return false, nil
+	}
+	if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	if failed {
+		return false, &homeDirJobError{name: job.Name}
+	}
+	return true, nil
+}
+
+// homeDirProvisionGaveUp reports whether provisioning the home directory
+// failed maxHomeDirProvisionFailures times at the current generation. A spec
+// change resets the failures so provisioning is retried.
+func homeDirProvisionGaveUp(user *sftpgov1alpha1.SftpGoUser) bool {
+	cond := meta.FindStatusCondition(user.Status.Conditions, "Ready")
+	if cond == nil || cond.Reason != "HomeDirProvisionFailed" {
+		return false
+	}
+	if cond.ObservedGeneration == user.Generation {
+		return true
+	}
+	user.Status.HomeDirProvisionFailures = 0
+	return false
+}
+
+// cleanupHomeDir applies the deletion policy to the home directory of a
+// deleted user and reports whether it is done. Users whose server is gone or
+// whose policy cannot be applied are left as is, and so are the users whose
+// cleanup failed maxHomeDirCleanupFailures times.
+func (r *SftpGoUserReconciler) cleanupHomeDir(ctx context.Context, user *sftpgov1alpha1.SftpGoUser) (bool, error) {
+	action := map[string]string{"Delete": homeDirDelete, "Archive": homeDirArchive}[homeDirOnDelete(user)]
+	if action == "" {
+		return true, nil
+	}
+	server := &sftpgov1alpha1.SftpGoServer{}
+	if err := r.Get(ctx, syncedServerKey(user), server); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	err := checkServerAccess(ctx, r.Client, user, server)
+	if err == nil {
+		err = validateHomeDirPolicy(user, server)
+	}
+	if err != nil {
+		r.Recorder.Eventf(user, corev1.EventTypeWarning, "HomeDirKept", "Home directory kept: %v", err)
+		return true, nil
+	}
+	done, err := r.runHomeDirJob(ctx, user, r.homeDirJob(user, server, action))
+	if _, failed := err.(*homeDirJobError); failed {
+		user.Status.HomeDirCleanupFailures++
+		if user.Status.HomeDirCleanupFailures >= maxHomeDirCleanupFailures {
+			r.Recorder.Eventf(user, corev1.EventTypeWarning, "HomeDirKept",
+				"Home directory kept after %d failed cleanups: %v", user.Status.HomeDirCleanupFailures, err)
+			return true, nil
+		}
+		if updateErr := r.Status().Update(ctx, user); updateErr != nil {
+			return false, updateErr
+		}
+	}
+	if done {
+		r.Recorder.Eventf(user, corev1.EventTypeNormal, "HomeDir"+homeDirOnDelete(user)+"d",
+			"Home directory %s %sd", user.Spec.HomeDir, strings.ToLower(homeDirOnDelete(user)))
+	}
+	return done, err
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+	"github.com/sftpgo/sftpgo-operator/internal/sftpgo"
+)
+
+// ImportOptions selects the SFTPGO users imported as SftpGoUsers
+type ImportOptions struct {
+	// Server is the SftpGoServer whose users are imported
+	Server types.NamespacedName
+	// Namespace of the SftpGoUsers, the server namespace when empty
+	Namespace string
+	// URL of the SFTPGO API, the in-cluster server Service when empty
+	URL string
+	// Adoption is the adoption annotation of the SftpGoUsers
+	Adoption string
+}
+
+// ImportedUser is an SftpGoUser generated from an SFTPGO user, with the
+// settings that could not be imported
+type ImportedUser struct {
+	User    *sftpgov1alpha1.SftpGoUser
+	Skipped []string
+	// AdoptionDowngraded is set when the user has skipped settings and its
+	// adoption was lowered from adopt to fail-if-exists, as adopting it
+	// would remove them
+	AdoptionDowngraded bool
+}
+
+// invalidNameChars are the characters replaced in SftpGoUser names
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// ImportUsers returns an SftpGoUser for each user of the server not managed
+// by an SftpGoUser yet
+func ImportUsers(ctx context.Context, c client.Client, opts ImportOptions) ([]ImportedUser, error) {
+	if _, err := adoptionPolicy(&sftpgov1alpha1.SftpGoUser{ObjectMeta: metav1.ObjectMeta{
+		Annotations: map[string]string{adoptionAnnotation: opts.Adoption}}}); err != nil {
+		return nil, err
+	}
+	server := &sftpgov1alpha1.SftpGoServer{}
+	if err := c.Get(ctx, opts.Server, server); err != nil {
+		return nil, err
+	}
+	username, password, err := adminCredentials(ctx, c, server)
+	if err != nil {
+		return nil, err
+	}
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("SftpGoServer %s has no admin Secret", opts.Server)
+	}
+	url := opts.URL
+	if url == "" {
+		url = serverAPIURL(server)
+	}
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = server.Namespace
+	}
+
+	existing := &sftpgov1alpha1.SftpGoUserList{}
+	if err := c.List(ctx, existing); err != nil {
+		return nil, err
+	}
+	managed := map[string]bool{}
+	names := map[string]bool{}
+	for i := range existing.Items {
+		u := &existing.Items[i]
+		if userServerKey(u) == opts.Server {
+			managed[u.Spec.Username] = true
+		}
+		if u.Namespace == namespace {
+			names[u.Name] = true
+		}
+	}
+
+	users, err := sftpgo.NewClient(url, username, password).ListUsers()
+	if err != nil {
+		return nil, err
+	}
+	var imported []ImportedUser
+	for i := range users {
+		if managed[users[i].Username] {
+			continue
+		}
+		spec, skipped := sftpgo.SpecFromUser(&users[i])
+		spec.ServerRef = sftpgov1alpha1.ServerRef{Name: server.Name}
+		if namespace != server.Namespace {
+			spec.ServerRef.Namespace = server.Namespace
+		}
+		name := importedUserName(spec.Username, names)
+		names[name] = true
+		adoption, downgraded := opts.Adoption, false
+		if adoption == adoptionAdopt && len(skipped) > 0 {
+			adoption, downgraded = adoptionFail, true
+		}
+		imported = append(imported, ImportedUser{
+			User: &sftpgov1alpha1.SftpGoUser{
+				TypeMeta: metav1.TypeMeta{APIVersion: sftpgov1alpha1.GroupVersion.String(), Kind: "SftpGoUser"},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        name,
+					Namespace:   namespace,
+					Annotations: map[string]string{adoptionAnnotation: adoption},
+				},
+				Spec: spec,
+			},
+			Skipped:            skipped,
+			AdoptionDowngraded: downgraded,
+		})
+	}
+	return imported, nil
+}
+
+// importedUserName returns a valid SftpGoUser name for a username, not among
+// the taken names
+func importedUserName(username string, taken map[string]bool) string {
+	base := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(username), "-"), "-.")
+	if len(base) > 240 {
+		base = strings.Trim(base[:240], "-.")
+	}
+	if base == "" {
+		base = "user"
+	}
+	name := base
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	return name
+}
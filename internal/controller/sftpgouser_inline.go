@@ -0,0 +1,152 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+// InlineSecretsPolicy is how secrets set in clear text in an SftpGoUser spec,
+// instead of referenced from a Secret, are handled
+type InlineSecretsPolicy string
+
+const (
+	// InlineSecretsAllow syncs inline secrets like referenced ones
+	InlineSecretsAllow InlineSecretsPolicy = "allow"
+	// InlineSecretsReject refuses to sync users with inline secrets
+	InlineSecretsReject InlineSecretsPolicy = "reject"
+	// InlineSecretsConvert moves inline secrets into a Secret owned by the
+	// user and makes the spec reference it
+	InlineSecretsConvert InlineSecretsPolicy = "convert"
+)
+
+// Keys of the Secret inline secrets are moved to
+const (
+	inlinePasswordKey = "password"
+	inlineSASURLKey   = "sasURL"
+)
+
+// inlineSecret is a secret set in clear text in a user spec
+type inlineSecret struct {
+	field string
+	key   string
+	value string
+	// replace clears the field and references ref instead
+	replace func(ref *sftpgov1alpha1.SecretRef)
+}
+
+// inlineSecretsName returns the name of the Secret inline secrets are moved to
+func inlineSecretsName(user *sftpgov1alpha1.SftpGoUser) string {
+	return user.Name + "-inline-secrets"
+}
+
+// inlineSecrets returns the secrets set in clear text in the user spec
+func inlineSecrets(user *sftpgov1alpha1.SftpGoUser) []inlineSecret {
+	spec := &user.Spec
+	var out []inlineSecret
+	if spec.Password != "" {
+		out = append(out, inlineSecret{
+			field: "spec.password",
+			key:   inlinePasswordKey,
+			value: spec.Password,
+			replace: func(ref *sftpgov1alpha1.SecretRef) {
+				spec.Password = ""
+				spec.PasswordSecretRef = ref
+			},
+		})
+	}
+	if fs := spec.Filesystem; fs != nil && fs.Azure != nil && fs.Azure.SASURL != "" {
+		azure := fs.Azure
+		out = append(out, inlineSecret{
+			field: "spec.filesystem.azure.sasURL",
+			key:   inlineSASURLKey,
+			value: azure.SASURL,
+			replace: func(ref *sftpgov1alpha1.SecretRef) {
+				azure.SASURL = ""
+				azure.SASURLSecretRef = ref
+			},
+		})
+	}
+	return out
+}
+
+// inlineSecretFields returns the spec paths of the inline secrets
+func inlineSecretFields(secrets []inlineSecret) string {
+	fields := make([]string, 0, len(secrets))
+	for _, s := range secrets {
+		fields = append(fields, s.field)
+	}
+	return strings.Join(fields, ", ")
+}
+
+// convertInlineSecrets moves the inline secrets of the user into its inline
+// secrets Secret and updates the spec to reference them. The
+// last-applied-configuration annotation of kubectl apply holds a copy of the
+// spec and is removed in the same update. The update triggers a new
+// reconcile of the user.
+func (r *SftpGoUserReconciler) convertInlineSecrets(ctx context.Context, user *sftpgov1alpha1.SftpGoUser, secrets []inlineSecret) error {
+	secret := &corev1.Secret{}
+	secret.Name = inlineSecretsName(user)
+	secret.Namespace = user.Namespace
+	if err := r.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+	} else if !metav1.IsControlledBy(secret, user) {
+		return fmt.Errorf("secret %s already exists and is not owned by the SftpGoUser", secret.Name)
+	}
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		secret.Type = corev1.SecretTypeOpaque
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		for _, s := range secrets {
+			secret.Data[s.key] = []byte(s.value)
+		}
+		return controllerutil.SetControllerReference(user, secret, r.Scheme)
+	}); err != nil {
+		return err
+	}
+
+	for _, s := range secrets {
+		s.replace(&sftpgov1alpha1.SecretRef{Name: secret.Name, Key: s.key})
+	}
+	_, applied := user.Annotations[corev1.LastAppliedConfigAnnotation]
+	delete(user.Annotations, corev1.LastAppliedConfigAnnotation)
+	if err := r.Update(ctx, user); err != nil {
+		return err
+	}
+	r.Recorder.Eventf(user, corev1.EventTypeNormal, "InlineSecretsConverted",
+		"Moved %s to Secret %s", inlineSecretFields(secrets), secret.Name)
+	if applied {
+		r.Recorder.Eventf(user, corev1.EventTypeWarning, "LastAppliedConfigurationRemoved",
+			"Removed the %s annotation holding %s, reference the Secret in the applied manifest instead",
+			corev1.LastAppliedConfigAnnotation, inlineSecretFields(secrets))
+	}
+	return nil
+}
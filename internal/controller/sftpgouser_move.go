@@ -0,0 +1,196 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+// parseServerKey parses a namespace/name server reference recorded in status
+func parseServerKey(s string) types.NamespacedName {
+	namespace, name, _ := strings.Cut(s, "/")
+	return types.NamespacedName{Namespace: namespace, Name: name}
+}
+
+// syncedServerKey returns the server the user account lives on, which lags
+// behind spec.serverRef while a move is in progress: the previous server
+// until the account is created on the target
+func syncedServerKey(user *sftpgov1alpha1.SftpGoUser) types.NamespacedName {
+	if user.Status.Server != "" {
+		return parseServerKey(user.Status.Server)
+	}
+	if user.Status.MovingFrom != "" {
+		return parseServerKey(user.Status.MovingFrom)
+	}
+	return userServerKey(user)
+}
+
+// startMove records the start of a move when spec.serverRef no longer points
+// to the server the user was synced to, and reports whether it did. The
+// account is then created on the target like a new user, so the adoption
+// policy applies there, while the ID of the account on the previous server
+// is kept to delete it.
+func startMove(user *sftpgov1alpha1.SftpGoUser) (bool, error) {
+	target := userServerKey(user).String()
+	synced := user.Status.Server
+	if synced == "" {
+		// Moving back before the account was created on the target cancels
+		// the move
+		if user.Status.MovingFrom != "" && user.Status.MovingFrom == target {
+			user.Status.Server = target
+			user.Status.UserID = user.Status.MovingFromUserID
+			user.Status.MovingFrom = ""
+			user.Status.MovingFromUserID = 0
+			meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+				Type:    "Moving",
+				Status:  metav1.ConditionFalse,
+				Reason:  "Cancelled",
+				Message: fmt.Sprintf("Stayed on %s", target),
+			})
+		}
+		return false, nil
+	}
+	if synced == target {
+		return false, nil
+	}
+	switch user.Status.MovingFrom {
+	case "":
+		user.Status.MovingFromUserID = user.Status.UserID
+		user.Status.UserID = 0
+		user.Status.Server = ""
+	case target:
+		// Moving back before the move completed: the account is still on
+		// the target, only the one on the intermediate server must go
+		user.Status.UserID, user.Status.MovingFromUserID = user.Status.MovingFromUserID, user.Status.UserID
+		user.Status.Server = target
+	default:
+		return false, fmt.Errorf("cannot move to %s before the move from %s to %s completes",
+			target, user.Status.MovingFrom, synced)
+	}
+	user.Status.MovingFrom = synced
+	meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+		Type:    "Moving",
+		Status:  metav1.ConditionTrue,
+		Reason:  "CreatingUser",
+		Message: fmt.Sprintf("Moving from %s to %s", synced, target),
+	})
+	return true, nil
+}
+
+// finishMove migrates the home directory from the previous server when asked
+// to and deletes the account there. It reports whether the move completed.
+func (r *SftpGoUserReconciler) finishMove(ctx context.Context, user *sftpgov1alpha1.SftpGoUser,
+	target *sftpgov1alpha1.SftpGoServer) (bool, error) {
+	from := user.Status.MovingFrom
+	source := &sftpgov1alpha1.SftpGoServer{}
+	if err := r.Get(ctx, parseServerKey(from), source); err != nil {
+		if !errors.IsNotFound(err) {
+			return false, err
+		}
+		// The account went away with its server, and so did its files
+		r.Recorder.Eventf(user, corev1.EventTypeWarning, "MoveSourceGone",
+			"SftpGoServer %s is gone, the user was not removed from it", from)
+		completeMove(user, from)
+		return true, nil
+	}
+	// The files on a source that no longer allows the user namespace are out
+	// of reach, the account there is still deleted
+	policy := user.Spec.HomeDirPolicy
+	migrate := policy != nil && policy.MigrateOnMove
+	if migrate {
+		allowed, err := serverAllowsNamespace(ctx, r.Client, source, user.Namespace)
+		if err != nil {
+			return false, err
+		}
+		if !allowed {
+			r.Recorder.Eventf(user, corev1.EventTypeWarning, "ReferenceNotPermitted",
+				"Home directory not migrated, %s no longer allows namespace %s", from, user.Namespace)
+			migrate = false
+		}
+	}
+
+	if migrate {
+		if moveFailed(user) {
+			return false, nil
+		}
+		err := validateMigration(user, source, target)
+		if err == nil {
+			err = r.checkMigrationVolumes(ctx, source, target)
+		}
+		if err != nil {
+			return false, err
+		}
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "Moving",
+			Status:  metav1.ConditionTrue,
+			Reason:  "MigratingFiles",
+			Message: fmt.Sprintf("Copying %s from %s", user.Spec.HomeDir, from),
+		})
+		done, err := r.runHomeDirJob(ctx, user, r.migrateHomeDirJob(user, source, target))
+		if _, failed := err.(*homeDirJobError); failed {
+			// Failed or timed out: left as is until the spec changes
+			meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+				Type:               "Moving",
+				Status:             metav1.ConditionTrue,
+				Reason:             "MigrationFailed",
+				Message:            fmt.Sprintf("Copying %s from %s failed, the account there is kept: %v", user.Spec.HomeDir, from, err),
+				ObservedGeneration: user.Generation,
+			})
+			r.Recorder.Eventf(user, corev1.EventTypeWarning, "MoveFailed", "Move from %s failed: %v", from, err)
+			return false, nil
+		}
+		if err != nil || !done {
+			return false, err
+		}
+	}
+
+	if err := r.deleteUserFrom(ctx, user, source, "moved"); err != nil {
+		return false, err
+	}
+	r.Recorder.Eventf(user, corev1.EventTypeNormal, "Moved", "Moved user from %s to %s", from, user.Status.Server)
+	completeMove(user, from)
+	return true, nil
+}
+
+// moveFailed reports whether the home directory migration of the move failed
+// at the current generation of the user. It is retried once the spec changes.
+func moveFailed(user *sftpgov1alpha1.SftpGoUser) bool {
+	cond := meta.FindStatusCondition(user.Status.Conditions, "Moving")
+	return cond != nil && cond.Reason == "MigrationFailed" && cond.ObservedGeneration == user.Generation
+}
+
+// completeMove clears the move from status
+func completeMove(user *sftpgov1alpha1.SftpGoUser, from string) {
+	user.Status.MovingFrom = ""
+	user.Status.MovingFromUserID = 0
+	meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+		Type:    "Moving",
+		Status:  metav1.ConditionFalse,
+		Reason:  "Completed",
+		Message: fmt.Sprintf("Moved from %s", from),
+	})
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultSecretsHashKeySecret is the default name of the Secret of the
+	// operator namespace holding the secrets hash key
+	DefaultSecretsHashKeySecret = "sftpgo-operator-secrets-hash-key"
+
+	// secretsHashKeyKey is the key of the secrets hash key Secret holding the
+	// HMAC key
+	secretsHashKeyKey  = "hmac-key"
+	secretsHashKeySize = 32
+)
+
+// LoadSecretsHashKey returns the key of the HMAC published in the
+// status.secretsHash of all the users, creating the Secret holding it with a
+// random key when missing. Without the key the hashes cannot be used to guess
+// the secrets offline.
+func LoadSecretsHashKey(ctx context.Context, c client.Client, key types.NamespacedName) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, key, secret); err == nil {
+		if len(secret.Data[secretsHashKeyKey]) == 0 {
+			return nil, fmt.Errorf("secret %s has no %s key", key, secretsHashKeyKey)
+		}
+		return secret.Data[secretsHashKeyKey], nil
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	hashKey := make([]byte, secretsHashKeySize)
+	if _, err := rand.Read(hashKey); err != nil {
+		return nil, err
+	}
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{secretsHashKeyKey: hashKey},
+	}
+	if err := c.Create(ctx, secret); errors.IsAlreadyExists(err) {
+		// Another replica created it first
+		return LoadSecretsHashKey(ctx, c, key)
+	} else if err != nil {
+		return nil, err
+	}
+	return hashKey, nil
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+	"github.com/sftpgo/sftpgo-operator/internal/sftpgo"
+)
+
+// disconnectReason returns why the sessions of an updated user must be
+// closed, empty when they are kept
+func disconnectReason(user *sftpgov1alpha1.SftpGoUser, desired, actual *sftpgo.UserPayload) string {
+	switch {
+	case desired.Status == 0 && actual.Status != 0:
+		return "disabled"
+	case user.Spec.DisconnectOnRestriction && sftpgo.Restricts(desired, actual):
+		return "restricted"
+	}
+	return ""
+}
+
+// disconnectUser closes the active sessions of a user and records how many
+// were closed. Failures are logged: the change itself was already applied.
+func (r *SftpGoUserReconciler) disconnectUser(ctx context.Context, c *sftpgo.Client, user *sftpgov1alpha1.SftpGoUser, reason string) {
+	log := logf.FromContext(ctx)
+	conns, err := c.GetConnections()
+	if err != nil {
+		log.Error(err, "Failed to list SFTPGO connections")
+		return
+	}
+	closed := 0
+	for _, conn := range sftpgo.UserConnections(conns, syncedUsername(user)) {
+		if err := c.CloseConnection(conn.ConnectionID); err != nil {
+			log.Error(err, "Failed to close SFTPGO connection", "connection", conn.ConnectionID)
+			continue
+		}
+		closed++
+	}
+	if closed > 0 {
+		r.Recorder.Eventf(user, corev1.EventTypeNormal, "Disconnected", "Closed %d active sessions, user %s", closed, reason)
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+	"github.com/sftpgo/sftpgo-operator/internal/sftpgo"
+)
+
+// pollUsage reads the usage of a synced user from SFTPGO. The user read
+// before the sync carries the usage, a user just created has none. The
+// session count is kept from the last poll when the connections cannot be
+// listed.
+func (r *SftpGoUserReconciler) pollUsage(ctx context.Context, c *sftpgo.Client, user *sftpgov1alpha1.SftpGoUser,
+	payload, existing *sftpgo.UserPayload) *sftpgov1alpha1.UserUsage {
+	var usage sftpgo.UserUsage
+	if existing != nil {
+		usage = existing.Usage
+	}
+	conns, err := c.GetConnections()
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list SFTPGO connections")
+	}
+	return userUsage(usage, payload, userSessions(user, conns, err), time.Now())
+}
+
+// userSessions returns the number of connections of the user, or the count
+// of the last poll when the connections could not be listed
+func userSessions(user *sftpgov1alpha1.SftpGoUser, conns []sftpgo.Connection, err error) int {
+	if err != nil {
+		if user.Status.Usage != nil {
+			return user.Status.Usage.ActiveSessions
+		}
+		return 0
+	}
+	return len(sftpgo.UserConnections(conns, syncedUsername(user)))
+}
+
+// pollServersUsage polls the usage of the users of every server each
+// UsageInterval until ctx is done. The polls only read from SFTPGO and patch
+// the usage status: syncing users is left to the reconcile and its resync.
+func (r *SftpGoUserReconciler) pollServersUsage(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+	ticker := time.NewTicker(r.UsageInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		servers := &sftpgov1alpha1.SftpGoServerList{}
+		if err := r.List(ctx, servers); err != nil {
+			log.Error(err, "Failed to list servers to poll usage")
+			continue
+		}
+		for i := range servers.Items {
+			server := &servers.Items[i]
+			key := client.ObjectKeyFromObject(server).String()
+			users := &sftpgov1alpha1.SftpGoUserList{}
+			if err := r.List(ctx, users, client.MatchingFields{userServerIndex: key}); err != nil {
+				log.Error(err, "Failed to list users of server", "server", key)
+				continue
+			}
+			synced := syncedUsers(key, users.Items)
+			if len(synced) == 0 {
+				continue
+			}
+			username, password, err := adminCredentials(ctx, r.Client, server)
+			if err != nil || username == "" || password == "" {
+				log.V(1).Info("Skipping usage poll, the admin credentials are not available", "server", key, "error", err)
+				continue
+			}
+			r.pollServerUsage(ctx, sftpgo.NewClient(serverAPIURL(server), username, password), synced)
+		}
+	}
+}
+
+// syncedUsers returns the users synced to the server at their current
+// generation. The others are left to their reconcile, which polls the usage
+// once they are synced.
+func syncedUsers(server string, users []sftpgov1alpha1.SftpGoUser) []*sftpgov1alpha1.SftpGoUser {
+	var synced []*sftpgov1alpha1.SftpGoUser
+	for i := range users {
+		user := &users[i]
+		if user.Status.Phase == "Synced" && user.Status.ObservedGeneration == user.Generation &&
+			user.Status.Server == server && user.DeletionTimestamp.IsZero() {
+			synced = append(synced, user)
+		}
+	}
+	return synced
+}
+
+// pollServerUsage patches the usage of users of the same server, listing the
+// server connections once
+func (r *SftpGoUserReconciler) pollServerUsage(ctx context.Context, c *sftpgo.Client, users []*sftpgov1alpha1.SftpGoUser) {
+	log := logf.FromContext(ctx)
+	conns, connsErr := c.GetConnections()
+	if connsErr != nil {
+		log.Error(connsErr, "Failed to list SFTPGO connections")
+	}
+	now := time.Now()
+	for _, user := range users {
+		existing, err := c.GetUser(user.Status.Username)
+		if err != nil {
+			log.Error(err, "Failed to get user usage from SFTPGO", "user", client.ObjectKeyFromObject(user))
+			continue
+		}
+		if existing == nil {
+			continue
+		}
+		patch := client.MergeFrom(user.DeepCopy())
+		user.Status.Usage = userUsage(existing.Usage, existing, userSessions(user, conns, connsErr), now)
+		if err := r.Status().Patch(ctx, user, patch); err != nil {
+			log.Error(err, "Failed to update user usage", "user", client.ObjectKeyFromObject(user))
+		}
+	}
+}
+
+// userUsage builds the usage status from the SFTPGO usage of a user and its
+// quota
+func userUsage(usage sftpgo.UserUsage, payload *sftpgo.UserPayload, sessions int, now time.Time) *sftpgov1alpha1.UserUsage {
+	status := &sftpgov1alpha1.UserUsage{
+		UsedQuotaSize:  usage.UsedQuotaSize,
+		UsedQuotaFiles: usage.UsedQuotaFiles,
+		LastLogin:      millisTime(usage.LastLogin),
+		FirstUpload:    millisTime(usage.FirstUpload),
+		FirstDownload:  millisTime(usage.FirstDownload),
+		ActiveSessions: sessions,
+		PolledAt:       &metav1.Time{Time: now},
+	}
+	percent, limited := int64(0), false
+	if payload.QuotaSize > 0 {
+		percent, limited = max(percent, usage.UsedQuotaSize*100/payload.QuotaSize), true
+	}
+	if payload.QuotaFiles > 0 {
+		percent, limited = max(percent, int64(usage.UsedQuotaFiles)*100/int64(payload.QuotaFiles)), true
+	}
+	if limited {
+		p := int32(percent)
+		status.QuotaPercent = &p
+	}
+	return status
+}
+
+func millisTime(ms int64) *metav1.Time {
+	t := sftpgo.MillisTime(ms)
+	if t.IsZero() {
+		return nil
+	}
+	return &metav1.Time{Time: t}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+// Field indexes used to find the users affected by a Secret or SftpGoServer change
+const (
+	// userSecretsIndex indexes SftpGoUsers by the names of the Secrets they reference
+	userSecretsIndex = ".spec.secretRefs"
+	// userServerIndex indexes SftpGoUsers by the namespace/name of their server
+	userServerIndex = ".spec.serverRef"
+	// serverAdminSecretIndex indexes SftpGoServers by the name of their admin Secret
+	serverAdminSecretIndex = ".spec.adminSecretRef.name"
+)
+
+// userServerKey returns the SftpGoServer referenced by a user, in the user
+// namespace unless set
+func userServerKey(user *sftpgov1alpha1.SftpGoUser) types.NamespacedName {
+	ns := user.Spec.ServerRef.Namespace
+	if ns == "" {
+		ns = user.Namespace
+	}
+	return types.NamespacedName{Name: user.Spec.ServerRef.Name, Namespace: ns}
+}
+
+// userSecretNames returns the names of the Secrets read or generated when
+// syncing a user
+func userSecretNames(user *sftpgov1alpha1.SftpGoUser) []string {
+	refs := []*sftpgov1alpha1.SecretRef{user.Spec.PasswordSecretRef, user.Spec.PasswordHashSecretRef, user.Spec.PublicKeysSecretRef}
+	if fs := user.Spec.Filesystem; fs != nil {
+		if fs.S3 != nil {
+			refs = append(refs, fs.S3.AccessSecret)
+		}
+		if fs.GCS != nil {
+			refs = append(refs, fs.GCS.Credentials)
+		}
+		if fs.Azure != nil {
+			refs = append(refs, fs.Azure.AccountKey, fs.Azure.SASURLSecretRef)
+		}
+		if fs.SFTP != nil {
+			refs = append(refs, fs.SFTP.Password, fs.SFTP.PrivateKey)
+		}
+		if fs.Crypt != nil {
+			refs = append(refs, fs.Crypt.Passphrase)
+		}
+	}
+	if user.Spec.GeneratedCredentials != nil {
+		refs = append(refs, &sftpgov1alpha1.SecretRef{Name: credentialsSecretName(user)})
+	}
+	seen := map[string]bool{}
+	var names []string
+	for _, ref := range refs {
+		if ref != nil && ref.Name != "" && !seen[ref.Name] {
+			seen[ref.Name] = true
+			names = append(names, ref.Name)
+		}
+	}
+	return names
+}
+
+// setupUserIndexes registers the field indexes of the user watches
+func setupUserIndexes(ctx context.Context, mgr ctrl.Manager) error {
+	indexer := mgr.GetFieldIndexer()
+	if err := indexer.IndexField(ctx, &sftpgov1alpha1.SftpGoUser{}, userSecretsIndex, func(obj client.Object) []string {
+		return userSecretNames(obj.(*sftpgov1alpha1.SftpGoUser))
+	}); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(ctx, &sftpgov1alpha1.SftpGoUser{}, userServerIndex, func(obj client.Object) []string {
+		return []string{userServerKey(obj.(*sftpgov1alpha1.SftpGoUser)).String()}
+	}); err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &sftpgov1alpha1.SftpGoServer{}, serverAdminSecretIndex, func(obj client.Object) []string {
+		server := obj.(*sftpgov1alpha1.SftpGoServer)
+		if server.Spec.AdminSecretRef == nil || server.Spec.AdminSecretRef.Name == "" {
+			return nil
+		}
+		return []string{server.Spec.AdminSecretRef.Name}
+	})
+}
+
+// usersForSecret enqueues the users reading the Secret, and the users of the
+// servers using it as admin Secret
+func (r *SftpGoUserReconciler) usersForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	users := &sftpgov1alpha1.SftpGoUserList{}
+	if err := r.List(ctx, users, client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{userSecretsIndex: obj.GetName()}); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list users referencing secret", "secret", obj.GetName())
+		return nil
+	}
+	requests := userRequests(users)
+
+	servers := &sftpgov1alpha1.SftpGoServerList{}
+	if err := r.List(ctx, servers, client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{serverAdminSecretIndex: obj.GetName()}); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list servers referencing secret", "secret", obj.GetName())
+		return requests
+	}
+	for i := range servers.Items {
+		requests = append(requests, r.usersForServer(ctx, &servers.Items[i])...)
+	}
+	return requests
+}
+
+// usersForServer enqueues the users of a server
+func (r *SftpGoUserReconciler) usersForServer(ctx context.Context, obj client.Object) []reconcile.Request {
+	users := &sftpgov1alpha1.SftpGoUserList{}
+	key := types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}
+	if err := r.List(ctx, users, client.MatchingFields{userServerIndex: key.String()}); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list users of server", "server", key)
+		return nil
+	}
+	return userRequests(users)
+}
+
+// usersForNamespace enqueues the users of a namespace referencing servers in
+// other namespaces, whose access depends on the namespace labels
+func (r *SftpGoUserReconciler) usersForNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	users := &sftpgov1alpha1.SftpGoUserList{}
+	if err := r.List(ctx, users, client.InNamespace(obj.GetName())); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list users of namespace", "namespace", obj.GetName())
+		return nil
+	}
+	remote := &sftpgov1alpha1.SftpGoUserList{}
+	for _, u := range users.Items {
+		if userServerKey(&u).Namespace != u.Namespace {
+			remote.Items = append(remote.Items, u)
+		}
+	}
+	return userRequests(remote)
+}
+
+func userRequests(users *sftpgov1alpha1.SftpGoUserList) []reconcile.Request {
+	requests := make([]reconcile.Request, 0, len(users.Items))
+	for _, u := range users.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: u.Name, Namespace: u.Namespace}})
+	}
+	return requests
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -75,9 +76,17 @@ type UserPayload struct {
 	UploadBandwidth   int64               `json:"upload_bandwidth,omitempty"`
 	DownloadBandwidth int64               `json:"download_bandwidth,omitempty"`
 	MaxSessions       int                 `json:"max_sessions,omitempty"`
-	AllowedIP         []string            `json:"allowed_ip,omitempty"`
-	DeniedIP          []string            `json:"denied_ip,omitempty"`
 	Groups            []GM                `json:"groups,omitempty"`
+	Filesystem        *Filesystem         `json:"filesystem,omitempty"`
+	Filters           *Filters            `json:"filters,omitempty"`
+	ExpirationDate    int64               `json:"expiration_date,omitempty"` // Unix ms, 0=never
+
+	// Not managed by the operator, kept as set in SFTPGO
+	Description    string `json:"description,omitempty"`
+	AdditionalInfo string `json:"additional_info,omitempty"`
+
+	// Usage is only filled by GetUser
+	Usage UserUsage `json:"-"`
 }
 
 type VF struct {
@@ -115,8 +124,15 @@ func (c *Client) GetUser(username string) (*UserPayload, error) {
 		return nil, fmt.Errorf("API returned %d", resp.StatusCode)
 	}
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
 	var user UserPayload
-	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+	if err := json.Unmarshal(body, &user); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &user.Usage); err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -169,7 +185,7 @@ func (c *Client) upsertUser(method, pathSuffix string, payload *UserPayload) (*U
 	return &user, nil
 }
 
-// DeleteUser deletes a user
+// DeleteUser deletes a user, already deleted users are ignored
 func (c *Client) DeleteUser(username string) error {
 	req, err := http.NewRequest(http.MethodDelete, c.BaseURL+"/api/v2/users/"+username, nil)
 	if err != nil {
@@ -185,7 +201,7 @@ func (c *Client) DeleteUser(username string) error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
 		return fmt.Errorf("API returned %d", resp.StatusCode)
 	}
 	return nil
@@ -290,15 +306,14 @@ func UserFromCR(spec *sftpgov1alpha1.SftpGoUserSpec, password string, publicKeys
 	if spec.MaxSessions > 0 {
 		p.MaxSessions = spec.MaxSessions
 	}
-	if len(spec.AllowedIP) > 0 {
-		p.AllowedIP = spec.AllowedIP
-	}
-	if len(spec.DeniedIP) > 0 {
-		p.DeniedIP = spec.DeniedIP
-	}
 	for _, g := range spec.Groups {
 		p.Groups = append(p.Groups, GM{Name: g, Type: 1})
 	}
+	// The encrypted filesystem stores its data under the home dir
+	if fs := spec.Filesystem; fs != nil && fs.Crypt != nil && fs.Crypt.PhysicalPath != "" &&
+		(fs.Provider == "crypt" || fs.Provider == "encrypted") {
+		p.HomeDir = fs.Crypt.PhysicalPath
+	}
 
 	return p
 }
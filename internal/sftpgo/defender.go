@@ -0,0 +1,64 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftpgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefenderHost is a host tracked by the SFTPGO defender
+type DefenderHost struct {
+	ID      string    `json:"id"`
+	IP      string    `json:"ip"`
+	Score   int       `json:"score,omitempty"`
+	BanTime time.Time `json:"ban_time,omitempty"`
+}
+
+// Banned reports whether the host is currently banned
+func (h *DefenderHost) Banned() bool {
+	return !h.BanTime.IsZero() && h.BanTime.After(time.Now())
+}
+
+// GetDefenderHosts lists the hosts that are banned or have a score
+func (c *Client) GetDefenderHosts() ([]DefenderHost, error) {
+	req, err := http.NewRequest(http.MethodGet, c.BaseURL+"/api/v2/defender/hosts", nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.setAuth(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned %d", resp.StatusCode)
+	}
+
+	var hosts []DefenderHost
+	if err := json.NewDecoder(resp.Body).Decode(&hosts); err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
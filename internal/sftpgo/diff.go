@@ -0,0 +1,259 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftpgo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// diffIgnored are the fields never compared: the ID is assigned by SFTPGO and
+// the password is not returned by the API
+var diffIgnored = map[string]bool{
+	"id":       true,
+	"password": true,
+}
+
+// DiffUser returns the JSON paths of the fields of the desired user that
+// differ from the user stored in SFTPGO, sorted. Both users are normalised the
+// way SFTPGO stores them: missing fields and zero values are equivalent, lists
+// are compared as sets and bandwidths are compared in KB/s, the API unit.
+// Secrets are only compared for presence since SFTPGO returns them encrypted,
+// use SecretsHash to detect their changes.
+func DiffUser(desired, actual *UserPayload) []string {
+	var diff []string
+	diffValues("", toJSONMap(desired), toJSONMap(actual), &diff)
+	sort.Strings(diff)
+	return diff
+}
+
+// KeepUnmanaged copies from the user stored in SFTPGO the fields the operator
+// does not manage, so that an update does not reset them
+func (p *UserPayload) KeepUnmanaged(actual *UserPayload) {
+	p.ID = actual.ID
+	p.Description = actual.Description
+	p.AdditionalInfo = actual.AdditionalInfo
+}
+
+// Restricts reports whether the desired user grants less access than the
+// user stored in SFTPGO: a permission removed from a directory, an allowed IP
+// list introduced or shrunk, a denied IP or a denied protocol added
+func Restricts(desired, actual *UserPayload) bool {
+	d, a := normalizeUser(desired), normalizeUser(actual)
+	for dir, perms := range a.Permissions {
+		want, ok := d.Permissions[dir]
+		if !ok {
+			continue // Inherited from the parent directory
+		}
+		if !containsAll(want, perms) {
+			return true
+		}
+	}
+	var df, af Filters
+	if d.Filters != nil {
+		df = *d.Filters
+	}
+	if a.Filters != nil {
+		af = *a.Filters
+	}
+	if len(df.AllowedIP) > 0 && (len(af.AllowedIP) == 0 || !containsAll(df.AllowedIP, af.AllowedIP)) {
+		return true
+	}
+	return !containsAll(af.DeniedIP, df.DeniedIP) || !containsAll(af.DeniedProtocols, df.DeniedProtocols)
+}
+
+// containsAll reports whether set holds every element of elems, "*" holding
+// everything
+func containsAll(set, elems []string) bool {
+	have := map[string]bool{}
+	for _, e := range set {
+		if e == "*" {
+			return true
+		}
+		have[e] = true
+	}
+	for _, e := range elems {
+		if !have[e] {
+			return false
+		}
+	}
+	return true
+}
+
+// SecretsHash returns an HMAC of the password and of the plain secrets of the
+// user, to detect secret changes SFTPGO cannot report. The key must be random
+// and kept secret, so that the hash cannot be used to guess the secrets.
+func SecretsHash(p *UserPayload, key []byte) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(p.Password))
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				if m, ok := v[k].(map[string]any); ok && isSecret(m) && m["status"] == "Plain" {
+					h.Write([]byte("\x00" + k + "\x00" + m["payload"].(string)))
+					continue
+				}
+				walk(v[k])
+			}
+		case []any:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	walk(toJSONMap(p))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func toJSONMap(u *UserPayload) map[string]any {
+	out := map[string]any{}
+	b, _ := json.Marshal(normalizeUser(u))
+	_ = json.Unmarshal(b, &out)
+	return out
+}
+
+// normalizeUser returns a copy of the user with the values SFTPGO rewrites on
+// save normalised: cleaned paths, trimmed keys and "*" standing for every
+// permission
+func normalizeUser(u *UserPayload) *UserPayload {
+	n := *u
+	if n.HomeDir != "" {
+		n.HomeDir = path.Clean(n.HomeDir)
+	}
+	n.PublicKeys = nil
+	for _, k := range u.PublicKeys {
+		n.PublicKeys = append(n.PublicKeys, strings.TrimSpace(k))
+	}
+	n.Permissions = map[string][]string{}
+	for dir, perms := range u.Permissions {
+		for _, perm := range perms {
+			if perm == "*" {
+				perms = []string{"*"}
+				break
+			}
+		}
+		n.Permissions[path.Clean(dir)] = perms
+	}
+	n.VirtualFolders = nil
+	for _, vf := range u.VirtualFolders {
+		vf.VirtualPath = path.Clean(vf.VirtualPath)
+		n.VirtualFolders = append(n.VirtualFolders, vf)
+	}
+	return &n
+}
+
+func diffValues(path string, desired, actual any, diff *[]string) {
+	if isZero(desired) && isZero(actual) {
+		return
+	}
+	dm, dIsMap := desired.(map[string]any)
+	am, aIsMap := actual.(map[string]any)
+	if dIsMap || aIsMap {
+		if isSecret(dm) || isSecret(am) {
+			if isZero(desired) != isZero(actual) {
+				*diff = append(*diff, path)
+			}
+			return
+		}
+		keys := map[string]bool{}
+		for k := range dm {
+			keys[k] = true
+		}
+		for k := range am {
+			keys[k] = true
+		}
+		for k := range keys {
+			if path == "" && diffIgnored[k] {
+				continue
+			}
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			diffValues(child, dm[k], am[k], diff)
+		}
+		return
+	}
+	if dl, ok := desired.([]any); ok {
+		desired = sortedSet(dl)
+	}
+	if al, ok := actual.([]any); ok {
+		actual = sortedSet(al)
+	}
+	if !reflect.DeepEqual(desired, actual) {
+		*diff = append(*diff, path)
+	}
+}
+
+// sortedSet returns the distinct elements of a JSON list sorted by their
+// encoding, SFTPGO does not preserve the order of lists
+func sortedSet(l []any) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, e := range l {
+		b, _ := json.Marshal(e)
+		if !seen[string(b)] {
+			seen[string(b)] = true
+			out = append(out, string(b))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// isSecret reports whether a JSON object is an SFTPGO secret
+func isSecret(m map[string]any) bool {
+	_, ok := m["status"].(string)
+	_, hasPayload := m["payload"]
+	return ok && hasPayload
+}
+
+func isZero(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		for _, e := range v {
+			if !isZero(e) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftpgo
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+var _ = Describe("Diff", func() {
+	Context("Drift detection", func() {
+		desired := func() *UserPayload {
+			p := UserFromCR(&sftpgov1alpha1.SftpGoUserSpec{
+				Username:    "alice",
+				HomeDir:     "/srv/alice",
+				Permissions: []string{"list", "download"},
+				Quota:       &sftpgov1alpha1.Quota{Size: 1024},
+			}, "secret", nil)
+			p.Filters = &Filters{}
+			p.Filesystem = &Filesystem{Provider: ProviderSFTP, SFTPConfig: &SFTPConfig{
+				Endpoint: "backup:22",
+				Password: PlainSecret("pw"),
+			}}
+			return p
+		}
+
+		It("ignores the ID, password, zero values and encrypted secrets", func() {
+			actual := desired()
+			actual.ID = 7
+			actual.Password = ""
+			actual.Filters = &Filters{Hooks: &HookFilter{}}
+			actual.Filesystem.SFTPConfig.Password = &Secret{Status: "AES-256-GCM", Payload: "xyz", Key: "k"}
+			Expect(DiffUser(desired(), actual)).To(BeEmpty())
+		})
+
+		It("reports the diverging fields", func() {
+			actual := desired()
+			actual.QuotaSize = 0
+			actual.Permissions["/"] = []string{"*"}
+			actual.Filters.DeniedIP = []string{"1.2.3.4/32"}
+			actual.Filesystem.SFTPConfig.Password = nil
+			Expect(DiffUser(desired(), actual)).To(Equal([]string{
+				"filesystem.sftpconfig.password",
+				"filters.denied_ip",
+				"permissions./",
+				"quota_size",
+			}))
+		})
+	})
+
+	Context("Update diffing", func() {
+		desired := func() *UserPayload {
+			spec := &sftpgov1alpha1.SftpGoUserSpec{
+				Username:        "alice",
+				HomeDir:         "/srv/alice/",
+				Permissions:     []string{"list", "*"},
+				PublicKeys:      []string{"ssh-ed25519 AAAA alice"},
+				Protocols:       []string{"SFTP", "HTTP"},
+				BandwidthLimits: &sftpgov1alpha1.BandwidthLimits{Upload: 1500},
+			}
+			p := UserFromCR(spec, "", spec.PublicKeys)
+			Expect(ApplyFilters(p, spec)).To(Succeed())
+			return p
+		}
+
+		It("compares the user the way SFTPGO stores it", func() {
+			actual := desired()
+			actual.HomeDir = "/srv/alice"
+			actual.Permissions["/"] = []string{"*"}
+			actual.PublicKeys = []string{"ssh-ed25519 AAAA alice\n"}
+			actual.Filters.DeniedProtocols = []string{"DAV", "FTP"}
+			Expect(actual.UploadBandwidth).To(Equal(int64(1)))
+			Expect(DiffUser(desired(), actual)).To(BeEmpty())
+		})
+
+		It("keeps the fields the operator does not manage", func() {
+			actual := desired()
+			actual.ID = 3
+			actual.Description = "set in WebAdmin"
+			actual.AdditionalInfo = "ticket 42"
+			p := desired()
+			Expect(DiffUser(p, actual)).To(Equal([]string{"additional_info", "description"}))
+			p.KeepUnmanaged(actual)
+			Expect(p.ID).To(Equal(3))
+			Expect(DiffUser(p, actual)).To(BeEmpty())
+		})
+
+		It("hashes the password and plain secrets only", func() {
+			p := desired()
+			p.Filesystem = &Filesystem{Provider: ProviderS3, S3Config: &S3Config{
+				Bucket:       "data",
+				AccessSecret: PlainSecret("s3cr3t"),
+			}}
+			key := []byte("key")
+			hash := SecretsHash(p, key)
+			Expect(SecretsHash(p, []byte("other key"))).NotTo(Equal(hash))
+			p.Email = "alice@example.com"
+			Expect(SecretsHash(p, key)).To(Equal(hash))
+			p.Filesystem.S3Config.AccessSecret = PlainSecret("rotated")
+			Expect(SecretsHash(p, key)).NotTo(Equal(hash))
+			p.Filesystem.S3Config.AccessSecret = PlainSecret("s3cr3t")
+			p.Password = "pw"
+			Expect(SecretsHash(p, key)).NotTo(Equal(hash))
+		})
+	})
+	Context("Restrictions", func() {
+		It("detects restricted permissions, IPs and protocols", func() {
+			actual := &UserPayload{Status: 1, Permissions: map[string][]string{"/": {"*"}}, Filters: &Filters{}}
+			desired := &UserPayload{Status: 1, Permissions: map[string][]string{"/": {"*"}}, Filters: &Filters{}}
+			Expect(Restricts(desired, actual)).To(BeFalse())
+
+			desired.Permissions["/"] = []string{"list", "download"}
+			Expect(Restricts(desired, actual)).To(BeTrue())
+			Expect(Restricts(actual, desired)).To(BeFalse())
+
+			desired.Permissions["/"] = []string{"*"}
+			desired.Filters.AllowedIP = []string{"10.0.0.0/8"}
+			Expect(Restricts(desired, actual)).To(BeTrue())
+			Expect(Restricts(actual, desired)).To(BeFalse())
+
+			desired.Filters.AllowedIP = nil
+			desired.Filters.DeniedProtocols = []string{"FTP"}
+			Expect(Restricts(desired, actual)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,211 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftpgo
+
+import (
+	"fmt"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+// SFTPGO filesystem providers
+const (
+	ProviderLocal  = 0
+	ProviderS3     = 1
+	ProviderGCS    = 2
+	ProviderAzBlob = 3
+	ProviderCrypt  = 4
+	ProviderSFTP   = 5
+)
+
+// providers maps the CR provider names to SFTPGO provider IDs
+var providers = map[string]int{
+	"":          ProviderLocal,
+	"osfs":      ProviderLocal,
+	"s3fs":      ProviderS3,
+	"gcsfs":     ProviderGCS,
+	"azureblob": ProviderAzBlob,
+	"crypt":     ProviderCrypt,
+	"encrypted": ProviderCrypt,
+	"sftpfs":    ProviderSFTP,
+}
+
+// Secret is an SFTPGO secret. Plain secrets are encrypted by SFTPGO on save;
+// secrets read back from the API are returned encrypted.
+type Secret struct {
+	Status         string `json:"status,omitempty"`
+	Payload        string `json:"payload,omitempty"`
+	Key            string `json:"key,omitempty"`
+	AdditionalData string `json:"additional_data,omitempty"`
+}
+
+// PlainSecret returns a plain text secret, or nil for an empty value
+func PlainSecret(value string) *Secret {
+	if value == "" {
+		return nil
+	}
+	return &Secret{Status: "Plain", Payload: value}
+}
+
+// Filesystem is the SFTPGO API user filesystem
+type Filesystem struct {
+	Provider     int           `json:"provider"`
+	S3Config     *S3Config     `json:"s3config,omitempty"`
+	GCSConfig    *GCSConfig    `json:"gcsconfig,omitempty"`
+	AzBlobConfig *AzBlobConfig `json:"azblobconfig,omitempty"`
+	CryptConfig  *CryptConfig  `json:"cryptconfig,omitempty"`
+	SFTPConfig   *SFTPConfig   `json:"sftpconfig,omitempty"`
+}
+
+type S3Config struct {
+	Bucket         string  `json:"bucket,omitempty"`
+	Region         string  `json:"region,omitempty"`
+	AccessKey      string  `json:"access_key,omitempty"`
+	AccessSecret   *Secret `json:"access_secret,omitempty"`
+	Endpoint       string  `json:"endpoint,omitempty"`
+	StorageClass   string  `json:"storage_class,omitempty"`
+	ACL            string  `json:"acl,omitempty"`
+	UploadPartSize int64   `json:"upload_part_size,omitempty"`
+	KeyPrefix      string  `json:"key_prefix,omitempty"`
+}
+
+type GCSConfig struct {
+	Bucket               string  `json:"bucket,omitempty"`
+	Credentials          *Secret `json:"credentials,omitempty"`
+	AutomaticCredentials int     `json:"automatic_credentials"`
+	StorageClass         string  `json:"storage_class,omitempty"`
+	KeyPrefix            string  `json:"key_prefix,omitempty"`
+}
+
+type AzBlobConfig struct {
+	Container      string  `json:"container,omitempty"`
+	AccountName    string  `json:"account_name,omitempty"`
+	AccountKey     *Secret `json:"account_key,omitempty"`
+	SASURL         *Secret `json:"sas_url,omitempty"`
+	Endpoint       string  `json:"endpoint,omitempty"`
+	UploadPartSize int64   `json:"upload_part_size,omitempty"`
+	KeyPrefix      string  `json:"key_prefix,omitempty"`
+}
+
+type CryptConfig struct {
+	Passphrase *Secret `json:"passphrase,omitempty"`
+}
+
+type SFTPConfig struct {
+	Endpoint   string  `json:"endpoint,omitempty"`
+	Username   string  `json:"username,omitempty"`
+	Password   *Secret `json:"password,omitempty"`
+	PrivateKey *Secret `json:"private_key,omitempty"`
+	Prefix     string  `json:"prefix,omitempty"`
+}
+
+// FilesystemSecrets holds the resolved values of the SecretRefs of a
+// FilesystemConfig
+type FilesystemSecrets struct {
+	S3AccessSecret  string
+	GCSCredentials  string
+	AzureAccountKey string
+	AzureSASURL     string
+	SFTPPassword    string
+	SFTPPrivateKey  string
+	CryptPassphrase string
+}
+
+// FilesystemFromCR converts the CR filesystem config to the API payload
+func FilesystemFromCR(fs *sftpgov1alpha1.FilesystemConfig, secrets FilesystemSecrets) (*Filesystem, error) {
+	if fs == nil {
+		return &Filesystem{Provider: ProviderLocal}, nil
+	}
+	provider, ok := providers[fs.Provider]
+	if !ok {
+		return nil, fmt.Errorf("unsupported filesystem provider %q", fs.Provider)
+	}
+	out := &Filesystem{Provider: provider}
+
+	switch provider {
+	case ProviderS3:
+		if fs.S3 == nil {
+			return nil, fmt.Errorf("filesystem provider %s requires filesystem.s3", fs.Provider)
+		}
+		out.S3Config = &S3Config{
+			Bucket:         fs.S3.Bucket,
+			Region:         fs.S3.Region,
+			AccessKey:      fs.S3.AccessKey,
+			AccessSecret:   PlainSecret(secrets.S3AccessSecret),
+			Endpoint:       fs.S3.Endpoint,
+			StorageClass:   fs.S3.StorageClass,
+			ACL:            fs.S3.ACL,
+			UploadPartSize: fs.S3.UploadPartSize,
+			KeyPrefix:      fs.S3.KeyPrefix,
+		}
+	case ProviderGCS:
+		if fs.GCS == nil {
+			return nil, fmt.Errorf("filesystem provider %s requires filesystem.gcs", fs.Provider)
+		}
+		if fs.GCS.CredentialsFile != "" && secrets.GCSCredentials == "" {
+			return nil, fmt.Errorf("filesystem.gcs.credentialsFile is not supported, use filesystem.gcs.credentials")
+		}
+		out.GCSConfig = &GCSConfig{
+			Bucket:       fs.GCS.Bucket,
+			Credentials:  PlainSecret(secrets.GCSCredentials),
+			StorageClass: fs.GCS.StorageClass,
+			KeyPrefix:    fs.GCS.KeyPrefix,
+		}
+		if secrets.GCSCredentials == "" {
+			// Use the pod's workload identity / application default credentials
+			out.GCSConfig.AutomaticCredentials = 1
+		}
+	case ProviderAzBlob:
+		if fs.Azure == nil {
+			return nil, fmt.Errorf("filesystem provider %s requires filesystem.azure", fs.Provider)
+		}
+		sasURL := fs.Azure.SASURL
+		if sasURL == "" {
+			sasURL = secrets.AzureSASURL
+		}
+		out.AzBlobConfig = &AzBlobConfig{
+			Container:      fs.Azure.Container,
+			AccountName:    fs.Azure.AccountName,
+			AccountKey:     PlainSecret(secrets.AzureAccountKey),
+			SASURL:         PlainSecret(sasURL),
+			Endpoint:       fs.Azure.EndpointSuffix,
+			UploadPartSize: fs.Azure.UploadBlockSize,
+			KeyPrefix:      fs.Azure.KeyPrefix,
+		}
+	case ProviderCrypt:
+		if secrets.CryptPassphrase == "" {
+			return nil, fmt.Errorf("filesystem provider %s requires filesystem.crypt.passphrase", fs.Provider)
+		}
+		out.CryptConfig = &CryptConfig{Passphrase: PlainSecret(secrets.CryptPassphrase)}
+	case ProviderSFTP:
+		if fs.SFTP == nil {
+			return nil, fmt.Errorf("filesystem provider %s requires filesystem.sftp", fs.Provider)
+		}
+		port := fs.SFTP.Port
+		if port == 0 {
+			port = 22
+		}
+		out.SFTPConfig = &SFTPConfig{
+			Endpoint:   fmt.Sprintf("%s:%d", fs.SFTP.Host, port),
+			Username:   fs.SFTP.Username,
+			Password:   PlainSecret(secrets.SFTPPassword),
+			PrivateKey: PlainSecret(secrets.SFTPPrivateKey),
+			Prefix:     fs.SFTP.RemotePath,
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftpgo
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+var _ = Describe("Filesystem", func() {
+	Context("Filesystem configuration", func() {
+		It("defaults to the local filesystem", func() {
+			fs, err := FilesystemFromCR(nil, FilesystemSecrets{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fs.Provider).To(Equal(ProviderLocal))
+		})
+
+		It("sends resolved secrets as plain SFTPGO secrets", func() {
+			fs, err := FilesystemFromCR(&sftpgov1alpha1.FilesystemConfig{
+				Provider: "s3fs",
+				S3: &sftpgov1alpha1.S3FilesystemConfig{
+					Bucket:       "data",
+					AccessKey:    "AKIA",
+					AccessSecret: &sftpgov1alpha1.SecretRef{Name: "s3", Key: "secret"},
+				},
+			}, FilesystemSecrets{S3AccessSecret: "s3cr3t"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fs.Provider).To(Equal(ProviderS3))
+			Expect(fs.S3Config.Bucket).To(Equal("data"))
+			Expect(fs.S3Config.AccessSecret).To(Equal(&Secret{Status: "Plain", Payload: "s3cr3t"}))
+		})
+
+		It("uses automatic GCS credentials without a credentials secret", func() {
+			fs, err := FilesystemFromCR(&sftpgov1alpha1.FilesystemConfig{
+				Provider: "gcsfs",
+				GCS:      &sftpgov1alpha1.GCSFilesystemConfig{Bucket: "data"},
+			}, FilesystemSecrets{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fs.GCSConfig.AutomaticCredentials).To(Equal(1))
+			Expect(fs.GCSConfig.Credentials).To(BeNil())
+		})
+
+		It("rejects a GCS credentials file path", func() {
+			_, err := FilesystemFromCR(&sftpgov1alpha1.FilesystemConfig{
+				Provider: "gcsfs",
+				GCS:      &sftpgov1alpha1.GCSFilesystemConfig{CredentialsFile: "/creds.json"},
+			}, FilesystemSecrets{})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("builds the SFTP endpoint with the default port", func() {
+			fs, err := FilesystemFromCR(&sftpgov1alpha1.FilesystemConfig{
+				Provider: "sftpfs",
+				SFTP:     &sftpgov1alpha1.SFTPFilesystemConfig{Host: "backup.example.com", Username: "u"},
+			}, FilesystemSecrets{SFTPPrivateKey: "KEY"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fs.SFTPConfig.Endpoint).To(Equal("backup.example.com:22"))
+			Expect(fs.SFTPConfig.Password).To(BeNil())
+			Expect(fs.SFTPConfig.PrivateKey.Payload).To(Equal("KEY"))
+		})
+
+		It("falls back to the referenced Azure SAS URL", func() {
+			fs, err := FilesystemFromCR(&sftpgov1alpha1.FilesystemConfig{
+				Provider: "azureblob",
+				Azure: &sftpgov1alpha1.AzureFilesystemConfig{
+					Container:       "data",
+					SASURLSecretRef: &sftpgov1alpha1.SecretRef{Name: "azure", Key: "sasURL"},
+				},
+			}, FilesystemSecrets{AzureSASURL: "https://example.blob.core.windows.net/?sig=x"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fs.AzBlobConfig.SASURL).To(Equal(&Secret{Status: "Plain", Payload: "https://example.blob.core.windows.net/?sig=x"}))
+		})
+
+		It("requires a passphrase for the crypt provider", func() {
+			_, err := FilesystemFromCR(&sftpgov1alpha1.FilesystemConfig{Provider: "crypt"}, FilesystemSecrets{})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("stores crypt data under the physical path", func() {
+			p := UserFromCR(&sftpgov1alpha1.SftpGoUserSpec{
+				Username: "alice",
+				HomeDir:  "/srv/alice",
+				Filesystem: &sftpgov1alpha1.FilesystemConfig{
+					Provider: "crypt",
+					Crypt:    &sftpgov1alpha1.CryptFilesystemConfig{PhysicalPath: "/srv/encrypted/alice"},
+				},
+			}, "", nil)
+			Expect(p.HomeDir).To(Equal("/srv/encrypted/alice"))
+		})
+	})
+})
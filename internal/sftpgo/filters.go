@@ -0,0 +1,217 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftpgo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+// SFTPGO protocol names, in the order used by the API
+var sftpgoProtocols = []string{"SSH", "FTP", "DAV", "HTTP"}
+
+// protocolAliases maps the CR protocol names to SFTPGO protocols
+var protocolAliases = map[string]string{
+	"ssh":    "SSH",
+	"sftp":   "SSH",
+	"scp":    "SSH",
+	"ftp":    "FTP",
+	"ftps":   "FTP",
+	"dav":    "DAV",
+	"webdav": "DAV",
+	"http":   "HTTP",
+	"https":  "HTTP",
+}
+
+// twoFactorProtocols are the protocols supporting TOTP in SFTPGO
+var twoFactorProtocols = map[string]bool{"SSH": true, "FTP": true, "HTTP": true}
+
+// externalAuthDisabled is the only per-user external auth hook setting SFTPGO supports
+const externalAuthDisabled = "disabled"
+
+// Filters is the SFTPGO API user "filters" object
+type Filters struct {
+	AllowedIP             []string         `json:"allowed_ip,omitempty"`
+	DeniedIP              []string         `json:"denied_ip,omitempty"`
+	DeniedProtocols       []string         `json:"denied_protocols,omitempty"`
+	TwoFactorProtocols    []string         `json:"two_factor_protocols,omitempty"`
+	RequirePasswordChange bool             `json:"require_password_change,omitempty"`
+	Hooks                 *HookFilter      `json:"hooks,omitempty"`
+	AccessTime            []TimePeriod     `json:"access_time,omitempty"`
+	FilePatterns          []PatternsFilter `json:"file_patterns,omitempty"`
+}
+
+type HookFilter struct {
+	ExternalAuthDisabled  bool `json:"external_auth_disabled"`
+	PreLoginDisabled      bool `json:"pre_login_disabled"`
+	CheckPasswordDisabled bool `json:"check_password_disabled"`
+}
+
+type TimePeriod struct {
+	DayOfWeek int    `json:"day_of_week"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+}
+
+// ApplyFilters sets the filters and transfer limits of the payload. All the
+// settings SFTPGO cannot represent are reported in the returned error.
+func ApplyFilters(p *UserPayload, spec *sftpgov1alpha1.SftpGoUserSpec) error {
+	var problems []string
+	f := &Filters{
+		AllowedIP:             spec.AllowedIP,
+		DeniedIP:              spec.DeniedIP,
+		RequirePasswordChange: spec.Filters.RequirePasswordChange,
+	}
+
+	allowed, err := allowedProtocols(spec.Protocols)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	for _, proto := range sftpgoProtocols {
+		if !allowed[proto] {
+			f.DeniedProtocols = append(f.DeniedProtocols, proto)
+		}
+	}
+
+	if spec.Filters.RequireTOTP {
+		for _, proto := range sftpgoProtocols {
+			if allowed[proto] && twoFactorProtocols[proto] {
+				f.TwoFactorProtocols = append(f.TwoFactorProtocols, proto)
+			}
+		}
+		if len(f.TwoFactorProtocols) == 0 {
+			problems = append(problems, "filters.requireTOTP needs SSH, FTP or HTTP among the allowed protocols")
+		}
+	}
+
+	switch spec.Filters.ExternalAuthHook {
+	case "":
+	case externalAuthDisabled:
+		f.Hooks = &HookFilter{ExternalAuthDisabled: true}
+	default:
+		problems = append(problems, fmt.Sprintf("filters.externalAuthHook %q is not supported: SFTPGO hooks are configured on the server (spec.auth.externalAuthHook), users can only set %q", spec.Filters.ExternalAuthHook, externalAuthDisabled))
+	}
+
+	if len(spec.Filters.AllowedCommands) > 0 {
+		problems = append(problems, "filters.allowedCommands is not supported: SFTPGO enables SSH commands for the whole server")
+	}
+
+	for i, ti := range spec.Filters.TimeIntervals {
+		periods, err := accessTime(ti)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("filters.timeIntervals[%d]: %v", i, err))
+			continue
+		}
+		f.AccessTime = append(f.AccessTime, periods...)
+	}
+
+	if spec.MaxConcurrentTransfers > 0 {
+		problems = append(problems, "maxConcurrentTransfers is not supported: SFTPGO limits sessions per user, use maxSessions")
+	}
+
+	if spec.RateLimits != nil {
+		if spec.RateLimits.Burst > 0 {
+			problems = append(problems, "rateLimits.burst is not supported: SFTPGO user bandwidth limits have no burst")
+		}
+		if spec.RateLimits.Average > 0 {
+			if spec.BandwidthLimits != nil && (spec.BandwidthLimits.Upload > 0 || spec.BandwidthLimits.Download > 0) {
+				problems = append(problems, "rateLimits.average conflicts with bandwidthLimits, set only one")
+			} else {
+				// SFTPGO API expects KB/s, where 0 means unlimited
+				p.UploadBandwidth = kbPerSecond(spec.RateLimits.Average)
+				p.DownloadBandwidth = kbPerSecond(spec.RateLimits.Average)
+			}
+		}
+	}
+
+	p.Filters = f
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// allowedProtocols returns the set of allowed SFTPGO protocols, all of them
+// when none is listed
+func allowedProtocols(protocols []string) (map[string]bool, error) {
+	allowed := map[string]bool{}
+	if len(protocols) == 0 {
+		for _, proto := range sftpgoProtocols {
+			allowed[proto] = true
+		}
+		return allowed, nil
+	}
+	var unknown []string
+	for _, name := range protocols {
+		proto, ok := protocolAliases[strings.ToLower(name)]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		allowed[proto] = true
+	}
+	if len(unknown) > 0 {
+		return allowed, fmt.Errorf("unknown protocols %s (supported: SFTP, FTP, WebDAV, HTTP)", strings.Join(unknown, ", "))
+	}
+	return allowed, nil
+}
+
+// accessTime converts a time interval to SFTPGO access periods. Intervals
+// crossing midnight are split over two days; no start and end means all day.
+func accessTime(ti sftpgov1alpha1.TimeInterval) ([]TimePeriod, error) {
+	if ti.Start < 0 || ti.Start > 23 || ti.End < 0 || ti.End > 23 {
+		return nil, fmt.Errorf("start and end must be hours between 0 and 23")
+	}
+	if ti.Start == ti.End && ti.Start != 0 {
+		return nil, fmt.Errorf("start and end are both %d, the interval is empty", ti.Start)
+	}
+	days := ti.Days
+	if len(days) == 0 {
+		days = []int{0, 1, 2, 3, 4, 5, 6}
+	}
+	var out []TimePeriod
+	for _, d := range days {
+		if d < 0 || d > 6 {
+			return nil, fmt.Errorf("day %d is not a day of week (0=Sunday to 6=Saturday)", d)
+		}
+		switch {
+		case ti.Start == 0 && ti.End == 0:
+			out = append(out, TimePeriod{DayOfWeek: d, From: "00:00", To: "23:59"})
+		case ti.Start < ti.End:
+			out = append(out, TimePeriod{DayOfWeek: d, From: hour(ti.Start), To: hour(ti.End)})
+		default:
+			out = append(out, TimePeriod{DayOfWeek: d, From: hour(ti.Start), To: "23:59"})
+			if ti.End > 0 {
+				out = append(out, TimePeriod{DayOfWeek: (d + 1) % 7, From: "00:00", To: hour(ti.End)})
+			}
+		}
+	}
+	return out, nil
+}
+
+func hour(h int) string {
+	return fmt.Sprintf("%02d:00", h)
+}
+
+// kbPerSecond converts a positive rate in bytes/s to KB/s, rounding up so
+// that rates below 1 KB/s do not turn into the unlimited 0
+func kbPerSecond(rate int64) int64 {
+	return (rate + 1023) / 1024
+}
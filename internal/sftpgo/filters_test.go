@@ -0,0 +1,93 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftpgo
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+var _ = Describe("Filters", func() {
+	Context("User filters", func() {
+		It("denies the protocols that are not allowed", func() {
+			p := &UserPayload{}
+			Expect(ApplyFilters(p, &sftpgov1alpha1.SftpGoUserSpec{
+				Protocols: []string{"SFTP", "webdav"},
+				AllowedIP: []string{"10.0.0.0/8"},
+				Filters:   sftpgov1alpha1.UserFilters{RequireTOTP: true, RequirePasswordChange: true},
+			})).To(Succeed())
+			Expect(p.Filters.DeniedProtocols).To(Equal([]string{"FTP", "HTTP"}))
+			Expect(p.Filters.TwoFactorProtocols).To(Equal([]string{"SSH"}))
+			Expect(p.Filters.AllowedIP).To(Equal([]string{"10.0.0.0/8"}))
+			Expect(p.Filters.RequirePasswordChange).To(BeTrue())
+		})
+
+		It("splits time intervals crossing midnight", func() {
+			p := &UserPayload{}
+			Expect(ApplyFilters(p, &sftpgov1alpha1.SftpGoUserSpec{
+				Filters: sftpgov1alpha1.UserFilters{TimeIntervals: []sftpgov1alpha1.TimeInterval{
+					{Start: 22, End: 6, Days: []int{6}},
+				}},
+			})).To(Succeed())
+			Expect(p.Filters.AccessTime).To(Equal([]TimePeriod{
+				{DayOfWeek: 6, From: "22:00", To: "23:59"},
+				{DayOfWeek: 0, From: "00:00", To: "06:00"},
+			}))
+		})
+
+		It("maps the average rate limit to bandwidth limits", func() {
+			p := &UserPayload{}
+			Expect(ApplyFilters(p, &sftpgov1alpha1.SftpGoUserSpec{
+				RateLimits: &sftpgov1alpha1.RateLimits{Average: 2048},
+			})).To(Succeed())
+			Expect(p.UploadBandwidth).To(Equal(int64(2)))
+			Expect(p.DownloadBandwidth).To(Equal(int64(2)))
+		})
+
+		It("rounds rate limits below 1 KB/s up instead of lifting them", func() {
+			p := &UserPayload{}
+			Expect(ApplyFilters(p, &sftpgov1alpha1.SftpGoUserSpec{
+				RateLimits: &sftpgov1alpha1.RateLimits{Average: 512},
+			})).To(Succeed())
+			Expect(p.UploadBandwidth).To(Equal(int64(1)))
+			Expect(p.DownloadBandwidth).To(Equal(int64(1)))
+
+			Expect(ApplyFilters(p, &sftpgov1alpha1.SftpGoUserSpec{
+				RateLimits: &sftpgov1alpha1.RateLimits{Average: 2049},
+			})).To(Succeed())
+			Expect(p.UploadBandwidth).To(Equal(int64(3)))
+		})
+
+		It("reports every setting SFTPGO cannot represent", func() {
+			err := ApplyFilters(&UserPayload{}, &sftpgov1alpha1.SftpGoUserSpec{
+				Protocols:              []string{"gopher"},
+				MaxConcurrentTransfers: 2,
+				RateLimits:             &sftpgov1alpha1.RateLimits{Burst: 10},
+				Filters: sftpgov1alpha1.UserFilters{
+					ExternalAuthHook: "http://hook",
+					AllowedCommands:  []string{"md5sum"},
+				},
+			})
+			Expect(err).To(HaveOccurred())
+			for _, field := range []string{"gopher", "maxConcurrentTransfers", "rateLimits.burst", "externalAuthHook", "allowedCommands"} {
+				Expect(err.Error()).To(ContainSubstring(field))
+			}
+		})
+	})
+})
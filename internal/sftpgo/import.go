@@ -0,0 +1,174 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftpgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+// listUsersLimit is the page size of ListUsers, the SFTPGO maximum
+const listUsersLimit = 500
+
+// crProtocols maps the SFTPGO protocols to their CR names
+var crProtocols = map[string]string{
+	"SSH":  "SFTP",
+	"FTP":  "FTP",
+	"DAV":  "WebDAV",
+	"HTTP": "HTTP",
+}
+
+// ListUsers lists all the users, sorted by username
+func (c *Client) ListUsers() ([]UserPayload, error) {
+	var users []UserPayload
+	for offset := 0; ; offset += listUsersLimit {
+		url := fmt.Sprintf("%s/api/v2/users?offset=%d&limit=%d&order=ASC", c.BaseURL, offset, listUsersLimit)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.setAuth(req); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		var page []UserPayload
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("API returned %d", resp.StatusCode)
+		} else {
+			err = json.NewDecoder(resp.Body).Decode(&page)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, page...)
+		if len(page) < listUsersLimit {
+			return users, nil
+		}
+	}
+}
+
+// SpecFromUser converts an SFTPGO user back to an SftpGoUser spec, the
+// reverse of UserFromCR and the Apply functions. The settings the spec
+// cannot carry, such as filesystem secrets SFTPGO only returns encrypted,
+// are reported instead.
+func SpecFromUser(p *UserPayload) (sftpgov1alpha1.SftpGoUserSpec, []string) {
+	var skipped []string
+	spec := sftpgov1alpha1.SftpGoUserSpec{
+		Username:    p.Username,
+		Email:       p.Email,
+		HomeDir:     p.HomeDir,
+		PublicKeys:  p.PublicKeys,
+		MaxSessions: p.MaxSessions,
+	}
+	if p.Status == 0 {
+		spec.Status = "disabled"
+	}
+
+	dirs := map[string]*sftpgov1alpha1.DirectoryPermissions{}
+	dir := func(path string) *sftpgov1alpha1.DirectoryPermissions {
+		if dirs[path] == nil {
+			dirs[path] = &sftpgov1alpha1.DirectoryPermissions{Path: path}
+		}
+		return dirs[path]
+	}
+	for path, perms := range p.Permissions {
+		if path == "/" {
+			if len(perms) != 1 || perms[0] != "*" {
+				spec.Permissions = perms
+			}
+			continue
+		}
+		dir(path).Permissions = perms
+	}
+
+	for _, vf := range p.VirtualFolders {
+		spec.VirtualFolders = append(spec.VirtualFolders, sftpgov1alpha1.VirtualFolder{
+			VirtualPath:  vf.VirtualPath,
+			PhysicalPath: vf.MappedPath,
+			Quota:        vf.QuotaSize,
+		})
+	}
+	if p.QuotaSize > 0 || p.QuotaFiles > 0 {
+		spec.Quota = &sftpgov1alpha1.Quota{Size: p.QuotaSize, Files: p.QuotaFiles}
+	}
+	if p.UploadBandwidth > 0 || p.DownloadBandwidth > 0 {
+		spec.BandwidthLimits = &sftpgov1alpha1.BandwidthLimits{
+			Upload:   p.UploadBandwidth * 1024,
+			Download: p.DownloadBandwidth * 1024,
+		}
+	}
+	for _, g := range p.Groups {
+		spec.Groups = append(spec.Groups, g.Name)
+	}
+	if p.ExpirationDate > 0 {
+		spec.ExpiresAt = &metav1.Time{Time: MillisTime(p.ExpirationDate)}
+	}
+	if p.Filesystem != nil && p.Filesystem.Provider != ProviderLocal {
+		skipped = append(skipped, fmt.Sprintf("filesystem: provider %d and its secrets are not exported", p.Filesystem.Provider))
+	}
+
+	if f := p.Filters; f != nil {
+		spec.AllowedIP = f.AllowedIP
+		spec.DeniedIP = f.DeniedIP
+		spec.Filters.RequirePasswordChange = f.RequirePasswordChange
+		spec.Filters.RequireTOTP = len(f.TwoFactorProtocols) > 0
+		if f.Hooks != nil && f.Hooks.ExternalAuthDisabled {
+			spec.Filters.ExternalAuthHook = externalAuthDisabled
+		}
+		if len(f.DeniedProtocols) > 0 {
+			denied := map[string]bool{}
+			for _, proto := range f.DeniedProtocols {
+				denied[proto] = true
+			}
+			for _, proto := range sftpgoProtocols {
+				if !denied[proto] {
+					spec.Protocols = append(spec.Protocols, crProtocols[proto])
+				}
+			}
+		}
+		for _, fp := range f.FilePatterns {
+			d := dir(fp.Path)
+			d.AllowedPatterns = fp.AllowedPatterns
+			d.DeniedPatterns = fp.DeniedPatterns
+			if fp.DenyPolicy == 1 {
+				d.DenyPolicy = "hide"
+			}
+		}
+		if len(f.AccessTime) > 0 {
+			skipped = append(skipped, "filters.access_time: time periods are not exported, set filters.timeIntervals")
+		}
+	}
+
+	for _, d := range dirs {
+		spec.DirectoryPermissions = append(spec.DirectoryPermissions, *d)
+	}
+	sort.Slice(spec.DirectoryPermissions, func(i, j int) bool {
+		return spec.DirectoryPermissions[i].Path < spec.DirectoryPermissions[j].Path
+	})
+	return spec, skipped
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftpgo
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+var _ = Describe("Import", func() {
+	Context("Import", func() {
+		It("converts SFTPGO users back to specs", func() {
+			spec := sftpgov1alpha1.SftpGoUserSpec{
+				Username:             "alice",
+				Status:               "disabled",
+				HomeDir:              "/srv/sftpgo/data/alice",
+				Permissions:          []string{"list", "download"},
+				DirectoryPermissions: []sftpgov1alpha1.DirectoryPermissions{{Path: "/in", Permissions: []string{"*"}, DeniedPatterns: []string{"*.exe"}, DenyPolicy: "hide"}},
+				Quota:                &sftpgov1alpha1.Quota{Size: 1 << 30},
+				BandwidthLimits:      &sftpgov1alpha1.BandwidthLimits{Upload: 2048},
+				AllowedIP:            []string{"10.0.0.0/8"},
+				Protocols:            []string{"SFTP", "HTTP"},
+				Groups:               []string{"staff"},
+				Filters:              sftpgov1alpha1.UserFilters{RequireTOTP: true},
+			}
+			payload := UserFromCR(&spec, "", nil)
+			Expect(ApplyFilters(payload, &spec)).To(Succeed())
+			Expect(ApplyDirectoryPermissions(payload, &spec)).To(Succeed())
+
+			imported, skipped := SpecFromUser(payload)
+			Expect(skipped).To(BeEmpty())
+			Expect(imported).To(Equal(spec))
+
+			payload.Filesystem = &Filesystem{Provider: ProviderS3}
+			_, skipped = SpecFromUser(payload)
+			Expect(skipped).To(ConsistOf(ContainSubstring("filesystem")))
+		})
+	})
+})
@@ -0,0 +1,136 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftpgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// IP list types and modes of the SFTPGO data provider
+const (
+	IPListTypeDefender = 2
+
+	IPListModeAllow = 1
+	IPListModeDeny  = 2
+)
+
+// ipListLimit is the page size of ListIPList, the SFTPGO maximum
+const ipListLimit = 500
+
+// IPListEntry is an entry of an SFTPGO IP list. Plain addresses are stored
+// as /32 or /128 networks.
+type IPListEntry struct {
+	IPOrNet     string `json:"ipornet"`
+	Description string `json:"description,omitempty"`
+	Type        int    `json:"type"`
+	Mode        int    `json:"mode"`
+	Protocols   int    `json:"protocols"`
+}
+
+// ListIPList lists all the entries of an IP list, sorted by network
+func (c *Client) ListIPList(listType int) ([]IPListEntry, error) {
+	var entries []IPListEntry
+	from := ""
+	for {
+		query := url.Values{"limit": {fmt.Sprint(ipListLimit)}, "order": {"ASC"}}
+		if from != "" {
+			query.Set("from", from)
+		}
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v2/iplists/%d?%s", c.BaseURL, listType, query.Encode()), nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.setAuth(req); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		var page []IPListEntry
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("API returned %d", resp.StatusCode)
+		} else {
+			err = json.NewDecoder(resp.Body).Decode(&page)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, page...)
+		if len(page) < ipListLimit {
+			return entries, nil
+		}
+		from = page[len(page)-1].IPOrNet
+	}
+}
+
+// AddIPListEntry adds an entry to its IP list
+func (c *Client) AddIPListEntry(entry IPListEntry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/v2/iplists/%d", c.BaseURL, entry.Type), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.setAuth(req); err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// DeleteIPListEntry removes an entry from an IP list, missing entries are
+// ignored
+func (c *Client) DeleteIPListEntry(listType int, ipOrNet string) error {
+	req, err := http.NewRequest(http.MethodDelete,
+		fmt.Sprintf("%s/api/v2/iplists/%d/%s", c.BaseURL, listType, url.PathEscape(ipOrNet)), nil)
+	if err != nil {
+		return err
+	}
+	if err := c.setAuth(req); err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("API returned %d", resp.StatusCode)
+	}
+	return nil
+}
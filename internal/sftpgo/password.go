@@ -0,0 +1,121 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftpgo
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Prefixes of the password hashes SFTPGO stores as they are instead of
+// hashing them as plain passwords
+const (
+	bcryptPrefix              = "$2a$"
+	argon2idPrefix            = "$argon2id$"
+	pbkdf2SHA1Prefix          = "$pbkdf2-sha1$"
+	pbkdf2SHA256Prefix        = "$pbkdf2-sha256$"
+	pbkdf2SHA512Prefix        = "$pbkdf2-sha512$"
+	pbkdf2B64SaltSHA256Prefix = "$pbkdf2-b64salt-sha256$"
+)
+
+// bcryptAlphabet is the base64 alphabet of bcrypt salts and hashes
+const bcryptAlphabet = "./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// ValidatePasswordHash checks that hash is a bcrypt, argon2id or pbkdf2
+// password hash in the format SFTPGO recognizes. Any other value would be
+// taken for a plain password and hashed again, locking the user out. The
+// errors never include the hash.
+func ValidatePasswordHash(hash string) error {
+	switch {
+	case strings.HasPrefix(hash, bcryptPrefix):
+		return validateBcryptHash(hash)
+	case strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return fmt.Errorf("SFTPGO only recognizes bcrypt hashes with the $2a$ prefix, replace %s with $2a$", hash[:4])
+	case strings.HasPrefix(hash, argon2idPrefix):
+		return validateArgon2idHash(hash)
+	case strings.HasPrefix(hash, pbkdf2SHA1Prefix), strings.HasPrefix(hash, pbkdf2SHA256Prefix),
+		strings.HasPrefix(hash, pbkdf2SHA512Prefix), strings.HasPrefix(hash, pbkdf2B64SaltSHA256Prefix):
+		return validatePbkdf2Hash(hash)
+	}
+	return fmt.Errorf("unsupported password hash, expected a bcrypt ($2a$), argon2id ($argon2id$) or pbkdf2 ($pbkdf2-sha256$, $pbkdf2-sha512$, $pbkdf2-sha1$, $pbkdf2-b64salt-sha256$) hash")
+}
+
+// validateBcryptHash checks a "$2a$<cost>$<salt and hash>" hash
+func validateBcryptHash(hash string) error {
+	if len(hash) != 60 || hash[6] != '$' {
+		return fmt.Errorf("invalid bcrypt hash, expected $2a$<cost>$ followed by 53 characters")
+	}
+	cost, err := strconv.Atoi(hash[4:6])
+	if err != nil || cost < 4 || cost > 31 {
+		return fmt.Errorf("invalid bcrypt hash, the cost must be between 04 and 31")
+	}
+	for _, c := range hash[7:] {
+		if !strings.ContainsRune(bcryptAlphabet, c) {
+			return fmt.Errorf("invalid bcrypt hash, unexpected characters after the cost")
+		}
+	}
+	return nil
+}
+
+// validateArgon2idHash checks a "$argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>"
+// hash, salt and key being unpadded base64
+func validateArgon2idHash(hash string) error {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return fmt.Errorf("invalid argon2id hash, expected $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>")
+	}
+	if parts[2] != "v=19" {
+		return fmt.Errorf("invalid argon2id hash, only version 19 is supported")
+	}
+	var memory, passes, threads uint64
+	if n, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &passes, &threads); err != nil || n != 3 ||
+		memory == 0 || passes == 0 || threads == 0 || threads > 255 {
+		return fmt.Errorf("invalid argon2id hash parameters, expected m=<memory>,t=<time>,p=<threads>")
+	}
+	for _, part := range parts[4:] {
+		if b, err := base64.RawStdEncoding.DecodeString(part); err != nil || len(b) == 0 {
+			return fmt.Errorf("invalid argon2id hash, the salt and key must be unpadded base64")
+		}
+	}
+	return nil
+}
+
+// validatePbkdf2Hash checks a "$pbkdf2-<digest>$<iterations>$<salt>$<key>"
+// hash, the key being base64
+func validatePbkdf2Hash(hash string) error {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 5 {
+		return fmt.Errorf("invalid pbkdf2 hash, expected $%s$<iterations>$<salt>$<key>", parts[1])
+	}
+	if iterations, err := strconv.Atoi(parts[2]); err != nil || iterations <= 0 {
+		return fmt.Errorf("invalid pbkdf2 hash, the iterations must be a positive number")
+	}
+	if parts[3] == "" {
+		return fmt.Errorf("invalid pbkdf2 hash, the salt is empty")
+	}
+	if "$"+parts[1]+"$" == pbkdf2B64SaltSHA256Prefix {
+		if _, err := base64.StdEncoding.DecodeString(parts[3]); err != nil {
+			return fmt.Errorf("invalid pbkdf2 hash, the salt must be base64")
+		}
+	}
+	if b, err := base64.StdEncoding.DecodeString(parts[4]); err != nil || len(b) == 0 {
+		return fmt.Errorf("invalid pbkdf2 hash, the key must be base64")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftpgo
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Password hashes", func() {
+	Context("Password hashes", func() {
+		const bcryptHash = "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+
+		It("accepts the hash formats SFTPGO recognizes", func() {
+			for _, hash := range []string{
+				bcryptHash,
+				"$argon2id$v=19$m=65536,t=1,p=2$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG",
+				"$pbkdf2-sha256$150000$E86a9YMX3zC7$R5J62hsSq+pYw00hLLPKBbcGXmq7fj5+/M0IFoYtZbo=",
+				"$pbkdf2-sha512$150000$E86a9YMX3zC7$R5J62hsSq+pYw00hLLPKBbcGXmq7fj5+/M0IFoYtZbo=",
+				"$pbkdf2-b64salt-sha256$150000$RTg2YTlZTVgzekM3$R5J62hsSq+pYw00hLLPKBbcGXmq7fj5+/M0IFoYtZbo=",
+			} {
+				Expect(ValidatePasswordHash(hash)).To(Succeed(), hash)
+			}
+		})
+
+		It("rejects plain passwords and malformed hashes", func() {
+			for hash, msg := range map[string]string{
+				"hunter2": "unsupported password hash",
+				"$2y$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy":                  "replace $2y$ with $2a$",
+				"$2a$10$N9qo8uLOickgx2ZMRZoMye":                                                 "followed by 53 characters",
+				"$2a$99$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy":                  "cost",
+				"$argon2id$v=16$m=65536,t=1,p=2$c29tZXNhbHQ$RdescudvJCsgt3ub":                   "version 19",
+				"$argon2id$v=19$m=65536$c29tZXNhbHQ$RdescudvJCsgt3ub":                           "parameters",
+				"$argon2id$v=19$m=65536,t=1,p=2$c29tZXNhbHQ$!!!":                                "unpadded base64",
+				"$pbkdf2-sha256$many$E86a9YMX3zC7$R5J62hsSq+pYw00hLLPKBbcGXmq7fj5+/M0IFoYtZbo=": "iterations",
+				"$pbkdf2-sha256$150000$E86a9YMX3zC7":                                            "expected $pbkdf2-sha256$",
+				"$pbkdf2-b64salt-sha256$150000$!!$R5J62hsSq+pYw00hLLPKBbcGXmq7fj5+/M0IFoYtZbo=": "salt must be base64",
+			} {
+				err := ValidatePasswordHash(hash)
+				Expect(err).To(MatchError(ContainSubstring(msg)), hash)
+				Expect(err.Error()).NotTo(ContainSubstring(hash))
+			}
+		})
+	})
+})
@@ -0,0 +1,122 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftpgo
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+// permissions is the SFTPGO permission vocabulary
+var permissions = map[string]bool{
+	"*":               true,
+	"list":            true,
+	"download":        true,
+	"upload":          true,
+	"overwrite":       true,
+	"delete":          true,
+	"delete_files":    true,
+	"delete_dirs":     true,
+	"rename":          true,
+	"rename_files":    true,
+	"rename_dirs":     true,
+	"create_dirs":     true,
+	"create_symlinks": true,
+	"chmod":           true,
+	"chown":           true,
+	"chtimes":         true,
+	"copy":            true,
+}
+
+// PatternsFilter is an entry of the SFTPGO user "file_patterns" filter
+type PatternsFilter struct {
+	Path            string   `json:"path"`
+	AllowedPatterns []string `json:"allowed_patterns,omitempty"`
+	DeniedPatterns  []string `json:"denied_patterns,omitempty"`
+	DenyPolicy      int      `json:"deny_policy,omitempty"` // 0=default, 1=hide
+}
+
+// ApplyDirectoryPermissions adds the per-directory permissions and file
+// patterns to the payload, after validating them against the SFTPGO
+// permission vocabulary. ApplyFilters must be called first.
+func ApplyDirectoryPermissions(p *UserPayload, spec *sftpgov1alpha1.SftpGoUserSpec) error {
+	var problems []string
+	if unknown := UnknownPermissions(spec.Permissions); len(unknown) > 0 {
+		problems = append(problems, fmt.Sprintf("permissions: unknown permissions %s", strings.Join(unknown, ", ")))
+	}
+
+	for i, dp := range spec.DirectoryPermissions {
+		field := fmt.Sprintf("directoryPermissions[%d]", i)
+		if !strings.HasPrefix(dp.Path, "/") || path.Clean(dp.Path) != dp.Path {
+			problems = append(problems, fmt.Sprintf("%s: path %q must be absolute and clean", field, dp.Path))
+			continue
+		}
+		if unknown := UnknownPermissions(dp.Permissions); len(unknown) > 0 {
+			problems = append(problems, fmt.Sprintf("%s: unknown permissions %s", field, strings.Join(unknown, ", ")))
+			continue
+		}
+		if len(dp.Permissions) > 0 {
+			if dp.Path == "/" && len(spec.Permissions) > 0 {
+				problems = append(problems, fmt.Sprintf("%s: permissions for / conflict with spec.permissions", field))
+				continue
+			}
+			p.Permissions[dp.Path] = dp.Permissions
+		}
+		if len(dp.AllowedPatterns) > 0 || len(dp.DeniedPatterns) > 0 {
+			filter := PatternsFilter{
+				Path:            dp.Path,
+				AllowedPatterns: dp.AllowedPatterns,
+				DeniedPatterns:  dp.DeniedPatterns,
+			}
+			if dp.DenyPolicy == "hide" {
+				filter.DenyPolicy = 1
+			}
+			p.Filters.FilePatterns = append(p.Filters.FilePatterns, filter)
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// UnknownPermissions returns the permissions SFTPGO does not know
+func UnknownPermissions(perms []string) []string {
+	var unknown []string
+	for _, perm := range perms {
+		if !permissions[perm] {
+			unknown = append(unknown, perm)
+		}
+	}
+	return unknown
+}
+
+// PermissionNames returns the SFTPGO permission vocabulary, sorted
+func PermissionNames() []string {
+	names := make([]string, 0, len(permissions))
+	for perm := range permissions {
+		names = append(names, perm)
+	}
+	sort.Strings(names)
+	return names
+}
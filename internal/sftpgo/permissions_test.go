@@ -0,0 +1,69 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftpgo
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+var _ = Describe("Directory permissions", func() {
+	Context("Directory permissions", func() {
+		spec := func(dirs ...sftpgov1alpha1.DirectoryPermissions) *sftpgov1alpha1.SftpGoUserSpec {
+			return &sftpgov1alpha1.SftpGoUserSpec{Username: "partner", Permissions: []string{"list"}, DirectoryPermissions: dirs}
+		}
+		payload := func(s *sftpgov1alpha1.SftpGoUserSpec) (*UserPayload, error) {
+			p := UserFromCR(s, "", nil)
+			Expect(ApplyFilters(p, s)).To(Succeed())
+			return p, ApplyDirectoryPermissions(p, s)
+		}
+
+		It("sends per-path permissions and file patterns", func() {
+			p, err := payload(spec(
+				sftpgov1alpha1.DirectoryPermissions{Path: "/in", Permissions: []string{"upload"}, AllowedPatterns: []string{"*.csv"}},
+				sftpgov1alpha1.DirectoryPermissions{Path: "/out", Permissions: []string{"list", "download"}, DeniedPatterns: []string{"*.tmp"}, DenyPolicy: "hide"},
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.Permissions).To(Equal(map[string][]string{
+				"/":    {"list"},
+				"/in":  {"upload"},
+				"/out": {"list", "download"},
+			}))
+			Expect(p.Filters.FilePatterns).To(Equal([]PatternsFilter{
+				{Path: "/in", AllowedPatterns: []string{"*.csv"}},
+				{Path: "/out", DeniedPatterns: []string{"*.tmp"}, DenyPolicy: 1},
+			}))
+		})
+
+		It("rejects permissions outside the SFTPGO vocabulary", func() {
+			_, err := payload(spec(sftpgov1alpha1.DirectoryPermissions{Path: "/in", Permissions: []string{"write"}}))
+			Expect(err).To(MatchError(ContainSubstring("unknown permissions write")))
+		})
+
+		It("rejects paths that are not clean", func() {
+			_, err := payload(spec(sftpgov1alpha1.DirectoryPermissions{Path: "/in/../out", Permissions: []string{"list"}}))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects root permissions set twice", func() {
+			_, err := payload(spec(sftpgov1alpha1.DirectoryPermissions{Path: "/", Permissions: []string{"*"}}))
+			Expect(err).To(MatchError(ContainSubstring("conflict with spec.permissions")))
+		})
+	})
+})
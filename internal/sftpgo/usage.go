@@ -0,0 +1,111 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftpgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// UserUsage is the usage SFTPGO tracks for a user. It is read-only: never
+// sent to SFTPGO nor compared.
+type UserUsage struct {
+	UsedQuotaSize  int64 `json:"used_quota_size"`
+	UsedQuotaFiles int   `json:"used_quota_files"`
+	LastLogin      int64 `json:"last_login"`     // Unix ms, 0=never
+	FirstUpload    int64 `json:"first_upload"`   // Unix ms, 0=never
+	FirstDownload  int64 `json:"first_download"` // Unix ms, 0=never
+}
+
+// Connection is an active SFTPGO connection
+type Connection struct {
+	ConnectionID string `json:"connection_id"`
+	Username     string `json:"username"`
+	Protocol     string `json:"protocol"`
+}
+
+// GetConnections lists the active connections
+func (c *Client) GetConnections() ([]Connection, error) {
+	req, err := http.NewRequest(http.MethodGet, c.BaseURL+"/api/v2/connections", nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.setAuth(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned %d", resp.StatusCode)
+	}
+
+	var conns []Connection
+	if err := json.NewDecoder(resp.Body).Decode(&conns); err != nil {
+		return nil, err
+	}
+	return conns, nil
+}
+
+// CloseConnection closes an active connection, already closed connections
+// are ignored
+func (c *Client) CloseConnection(connectionID string) error {
+	req, err := http.NewRequest(http.MethodDelete, c.BaseURL+"/api/v2/connections/"+url.PathEscape(connectionID), nil)
+	if err != nil {
+		return err
+	}
+	if err := c.setAuth(req); err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("API returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// UserConnections returns the connections of a user
+func UserConnections(conns []Connection, username string) []Connection {
+	var out []Connection
+	for _, conn := range conns {
+		if conn.Username == username {
+			out = append(out, conn)
+		}
+	}
+	return out
+}
+
+// MillisTime converts an SFTPGO Unix ms timestamp, zero meaning never
+func MillisTime(ms int64) time.Time {
+	if ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
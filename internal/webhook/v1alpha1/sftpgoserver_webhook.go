@@ -0,0 +1,253 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var sftpgoserverlog = logf.Log.WithName("sftpgoserver-resource")
+
+// SetupSftpGoServerWebhookWithManager registers the webhook for SftpGoServer in the manager.
+func SetupSftpGoServerWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&sftpgov1alpha1.SftpGoServer{}).
+		WithValidator(&SftpGoServerCustomValidator{}).
+		WithDefaulter(&SftpGoServerCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-sftpgo-sftpgo-io-v1alpha1-sftpgoserver,mutating=true,failurePolicy=fail,sideEffects=None,groups=sftpgo.sftpgo.io,resources=sftpgoservers,verbs=create;update,versions=v1alpha1,name=msftpgoserver-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// SftpGoServerCustomDefaulter sets default values on the SftpGoServer
+// resource when it is created or updated.
+type SftpGoServerCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &SftpGoServerCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind SftpGoServer.
+func (d *SftpGoServerCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	server, ok := obj.(*sftpgov1alpha1.SftpGoServer)
+	if !ok {
+		return fmt.Errorf("expected an SftpGoServer object but got %T", obj)
+	}
+	sftpgoserverlog.V(1).Info("Defaulting", "name", server.GetName())
+
+	spec := &server.Spec
+	if spec.Replicas == nil {
+		one := int32(1)
+		spec.Replicas = &one
+	}
+	// The config ports override the top level ones, keep both in line
+	if spec.SFTPPort == 0 {
+		spec.SFTPPort = sftpgov1alpha1.DefaultSFTPPort
+		if spec.Config.SFTP != nil && spec.Config.SFTP.Port > 0 {
+			spec.SFTPPort = spec.Config.SFTP.Port
+		}
+	}
+	if spec.WebPort == 0 {
+		spec.WebPort = sftpgov1alpha1.DefaultWebPort
+		if spec.Config.HTTP != nil && spec.Config.HTTP.Port > 0 {
+			spec.WebPort = spec.Config.HTTP.Port
+		}
+	}
+	if spec.StorageBackend == "" {
+		spec.StorageBackend = "sqlite"
+	}
+	if spec.UserManagement == "" {
+		spec.UserManagement = "shared"
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-sftpgo-sftpgo-io-v1alpha1-sftpgoserver,mutating=false,failurePolicy=fail,sideEffects=None,groups=sftpgo.sftpgo.io,resources=sftpgoservers,verbs=create;update,versions=v1alpha1,name=vsftpgoserver-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// SftpGoServerCustomValidator validates the SftpGoServer resource when it is
+// created or updated.
+type SftpGoServerCustomValidator struct{}
+
+var _ webhook.CustomValidator = &SftpGoServerCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type SftpGoServer.
+func (v *SftpGoServerCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	server, ok := obj.(*sftpgov1alpha1.SftpGoServer)
+	if !ok {
+		return nil, fmt.Errorf("expected an SftpGoServer object but got %T", obj)
+	}
+	return nil, validateSftpGoServer(server)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type SftpGoServer.
+func (v *SftpGoServerCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	server, ok := newObj.(*sftpgov1alpha1.SftpGoServer)
+	if !ok {
+		return nil, fmt.Errorf("expected an SftpGoServer object for the newObj but got %T", newObj)
+	}
+	return nil, validateSftpGoServer(server)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type SftpGoServer.
+func (v *SftpGoServerCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateSftpGoServer returns an Invalid error listing the inconsistent
+// fields of the server
+func validateSftpGoServer(server *sftpgov1alpha1.SftpGoServer) error {
+	allErrs := validateSftpGoServerSpec(&server.Spec, field.NewPath("spec"))
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(sftpgov1alpha1.GroupVersion.WithKind("SftpGoServer").GroupKind(), server.Name, allErrs)
+}
+
+func validateSftpGoServerSpec(spec *sftpgov1alpha1.SftpGoServerSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := validateServerPorts(spec, fldPath)
+
+	if svc := spec.Service; svc != nil {
+		allErrs = append(allErrs, validateCIDRs(fldPath.Child("service", "loadBalancerSourceRanges"), svc.LoadBalancerSourceRanges)...)
+	}
+	if pp := spec.ProxyProtocol; pp != nil {
+		allErrs = append(allErrs, validateIPsOrCIDRs(fldPath.Child("proxyProtocol", "allowed"), pp.Allowed)...)
+		allErrs = append(allErrs, validateIPsOrCIDRs(fldPath.Child("proxyProtocol", "skipped"), pp.Skipped)...)
+	}
+	if d := spec.Config.Defender; d != nil {
+		defenderPath := fldPath.Child("config", "defender")
+		allErrs = append(allErrs, validateIPsOrCIDRs(defenderPath.Child("safelist"), d.Safelist)...)
+		allErrs = append(allErrs, validateIPsOrCIDRs(defenderPath.Child("blocklist"), d.Blocklist)...)
+	}
+
+	if (spec.StorageBackend == "mysql" || spec.StorageBackend == "postgres") && spec.Database == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("database"), "required by the "+spec.StorageBackend+" storage backend"))
+	}
+	if spec.UserManagement == "exclusive" && spec.AdminSecretRef == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("adminSecretRef"), "required by exclusive user management"))
+	}
+	if pruning := spec.UserPruning; pruning != nil {
+		for i, pattern := range pruning.ProtectedUsers {
+			if _, err := path.Match(pattern, ""); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("userPruning", "protectedUsers").Index(i), pattern, err.Error()))
+			}
+		}
+	}
+	names := map[string]bool{}
+	for i, plugin := range spec.Plugins {
+		namePath := fldPath.Child("plugins").Index(i).Child("name")
+		switch {
+		case plugin.Name == sftpgov1alpha1.LDAPAuthPluginName:
+			allErrs = append(allErrs, field.Invalid(namePath, plugin.Name, "reserved for the LDAP authentication plugin"))
+		case names[plugin.Name]:
+			allErrs = append(allErrs, field.Duplicate(namePath, plugin.Name))
+		}
+		names[plugin.Name] = true
+	}
+	if sel := spec.AllowedUserNamespaces; sel != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(sel,
+			metav1validation.LabelSelectorValidationOptions{}, fldPath.Child("allowedUserNamespaces"))...)
+	}
+	return allErrs
+}
+
+// validateServerPorts checks that the config ports agree with the top level
+// ones and that the enabled services listen on distinct ports
+func validateServerPorts(spec *sftpgov1alpha1.SftpGoServerSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	configPath := fldPath.Child("config")
+
+	sftpPort, sftpPath := spec.SFTPPort, fldPath.Child("sftpPort")
+	if c := spec.Config.SFTP; c != nil && c.Port != 0 {
+		allErrs = append(allErrs, validatePort(configPath.Child("sftp", "port"), c.Port)...)
+		if sftpPort != 0 && sftpPort != c.Port {
+			allErrs = append(allErrs, field.Invalid(configPath.Child("sftp", "port"), c.Port, "conflicts with spec.sftpPort"))
+		}
+		sftpPort, sftpPath = c.Port, configPath.Child("sftp", "port")
+	}
+	if sftpPort == 0 {
+		sftpPort = sftpgov1alpha1.DefaultSFTPPort
+	}
+	webPort, webPath := spec.WebPort, fldPath.Child("webPort")
+	if c := spec.Config.HTTP; c != nil && c.Port != 0 {
+		allErrs = append(allErrs, validatePort(configPath.Child("http", "port"), c.Port)...)
+		if webPort != 0 && webPort != c.Port {
+			allErrs = append(allErrs, field.Invalid(configPath.Child("http", "port"), c.Port, "conflicts with spec.webPort"))
+		}
+		webPort, webPath = c.Port, configPath.Child("http", "port")
+	}
+	if webPort == 0 {
+		webPort = sftpgov1alpha1.DefaultWebPort
+	}
+	if sftpPort == webPort {
+		allErrs = append(allErrs, field.Invalid(webPath, webPort, fmt.Sprintf("must differ from the SFTP port (%s)", sftpPath)))
+	}
+
+	used := map[int32]string{sftpPort: "SFTP", webPort: "web"}
+	if c := spec.Config.FTP; c != nil {
+		portPath := configPath.Child("ftp", "port")
+		allErrs = append(allErrs, validatePort(portPath, c.Port)...)
+		port := c.Port
+		if port == 0 {
+			port = sftpgov1alpha1.DefaultFTPPort
+		}
+		if c.Enabled {
+			if other, ok := used[port]; ok {
+				allErrs = append(allErrs, field.Invalid(portPath, port, fmt.Sprintf("conflicts with the %s port", other)))
+			}
+			used[port] = "FTP"
+		}
+		allErrs = append(allErrs, validatePortRange(configPath.Child("ftp", "passivePortRange"), c.PassivePortRange)...)
+		allErrs = append(allErrs, validatePortRange(configPath.Child("ftp", "activePortRange"), c.ActivePortRange)...)
+	}
+	if c := spec.Config.WebDAV; c != nil && c.Port != 0 {
+		portPath := configPath.Child("webdav", "port")
+		allErrs = append(allErrs, validatePort(portPath, c.Port)...)
+		if other, ok := used[c.Port]; ok && c.Enabled {
+			allErrs = append(allErrs, field.Invalid(portPath, c.Port, fmt.Sprintf("conflicts with the %s port", other)))
+		}
+	}
+	return allErrs
+}
+
+func validatePortRange(fldPath *field.Path, r *sftpgov1alpha1.PortRange) field.ErrorList {
+	if r == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	if r.Start < 1 || r.Start > 65535 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("start"), r.Start, "must be between 1 and 65535"))
+	}
+	if r.End < 1 || r.End > 65535 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("end"), r.End, "must be between 1 and 65535"))
+	}
+	if r.End < r.Start {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("end"), r.End, "must not be lower than start"))
+	}
+	return allErrs
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+var _ = Describe("SftpGoServer Webhook", func() {
+	var (
+		ctx       context.Context
+		server    *sftpgov1alpha1.SftpGoServer
+		validator SftpGoServerCustomValidator
+		defaulter SftpGoServerCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = &sftpgov1alpha1.SftpGoServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "files", Namespace: "default"},
+		}
+	})
+
+	causes := func(err error) []string {
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		var fields []string
+		for _, cause := range err.(*apierrors.StatusError).ErrStatus.Details.Causes {
+			fields = append(fields, cause.Field)
+		}
+		return fields
+	}
+
+	Context("When creating SftpGoServer under Defaulting Webhook", func() {
+		It("fills in the defaults the controller applies", func() {
+			Expect(defaulter.Default(ctx, server)).To(Succeed())
+			Expect(*server.Spec.Replicas).To(Equal(int32(1)))
+			Expect(server.Spec.SFTPPort).To(Equal(int32(2022)))
+			Expect(server.Spec.WebPort).To(Equal(int32(8080)))
+			Expect(server.Spec.StorageBackend).To(Equal("sqlite"))
+			Expect(server.Spec.UserManagement).To(Equal("shared"))
+			Expect(validator.ValidateCreate(ctx, server)).Error().NotTo(HaveOccurred())
+		})
+
+		It("takes the ports from the config when set there", func() {
+			server.Spec.Config.SFTP = &sftpgov1alpha1.SFTPConfig{Port: 3022}
+			Expect(defaulter.Default(ctx, server)).To(Succeed())
+			Expect(server.Spec.SFTPPort).To(Equal(int32(3022)))
+			Expect(validator.ValidateCreate(ctx, server)).Error().NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When creating or updating SftpGoServer under Validating Webhook", func() {
+		It("rejects services sharing a port", func() {
+			server.Spec.SFTPPort = 8080
+			_, err := validator.ValidateCreate(ctx, server)
+			Expect(causes(err)).To(ConsistOf("spec.webPort"))
+
+			server.Spec.SFTPPort = 2022
+			server.Spec.Config.HTTP = &sftpgov1alpha1.HTTPConfig{Port: 9090}
+			server.Spec.WebPort = 8080
+			server.Spec.Config.FTP = &sftpgov1alpha1.FTPConfig{
+				Enabled:          true,
+				Port:             2022,
+				PassivePortRange: &sftpgov1alpha1.PortRange{Start: 50100, End: 50000},
+			}
+			server.Spec.Config.WebDAV = &sftpgov1alpha1.WebDAVConfig{Enabled: true, Port: 9090}
+			_, err = validator.ValidateUpdate(ctx, server, server)
+			Expect(causes(err)).To(ConsistOf(
+				"spec.config.http.port",
+				"spec.config.ftp.port",
+				"spec.config.ftp.passivePortRange.end",
+				"spec.config.webdav.port",
+			))
+		})
+
+		It("rejects malformed networks and missing dependencies", func() {
+			server.Spec.Service = &sftpgov1alpha1.ServiceConfig{LoadBalancerSourceRanges: []string{"10.0.0.0/8", "10.0.0.1"}}
+			server.Spec.ProxyProtocol = &sftpgov1alpha1.ProxyProtocolConfig{Mode: "required", Allowed: []string{"10.0.0.1", "lb"}}
+			server.Spec.Config.Defender = &sftpgov1alpha1.DefenderConfig{Safelist: []string{"192.168.0.0/16"}, Blocklist: []string{"1.2.3.4/40"}}
+			server.Spec.StorageBackend = "postgres"
+			server.Spec.UserManagement = "exclusive"
+			server.Spec.UserPruning = &sftpgov1alpha1.UserPruningConfig{ProtectedUsers: []string{"admin-*", "[a-"}}
+			server.Spec.AllowedUserNamespaces = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "team", Operator: metav1.LabelSelectorOpIn},
+			}}
+			_, err := validator.ValidateCreate(ctx, server)
+			Expect(causes(err)).To(ConsistOf(
+				"spec.service.loadBalancerSourceRanges[1]",
+				"spec.proxyProtocol.allowed[1]",
+				"spec.config.defender.blocklist[0]",
+				"spec.database",
+				"spec.adminSecretRef",
+				"spec.userPruning.protectedUsers[1]",
+				"spec.allowedUserNamespaces.matchExpressions[0].values",
+			))
+
+			server.Spec.Database = &sftpgov1alpha1.DatabaseConfig{}
+			server.Spec.AdminSecretRef = &corev1.LocalObjectReference{Name: "admin"}
+			server.Spec.Service = nil
+			server.Spec.ProxyProtocol = nil
+			server.Spec.Config.Defender = nil
+			server.Spec.UserPruning = nil
+			server.Spec.AllowedUserNamespaces = nil
+			Expect(validator.ValidateCreate(ctx, server)).Error().NotTo(HaveOccurred())
+		})
+
+		It("rejects duplicate and reserved plugin names", func() {
+			server.Spec.Plugins = []sftpgov1alpha1.PluginConfig{
+				{Name: "events", Type: "notifier", Binary: "/bin/a"},
+				{Name: "events", Type: "notifier", Binary: "/bin/b"},
+				{Name: sftpgov1alpha1.LDAPAuthPluginName, Type: "auth", Binary: "/bin/c"},
+			}
+			_, err := validator.ValidateCreate(ctx, server)
+			Expect(causes(err)).To(ConsistOf("spec.plugins[1].name", "spec.plugins[2].name"))
+		})
+	})
+})
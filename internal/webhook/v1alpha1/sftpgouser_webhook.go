@@ -0,0 +1,201 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+	"github.com/sftpgo/sftpgo-operator/internal/sftpgo"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var sftpgouserlog = logf.Log.WithName("sftpgouser-resource")
+
+// SetupSftpGoUserWebhookWithManager registers the webhook for SftpGoUser in the manager.
+// rejectInlineSecrets refuses users with secrets set in clear text in their spec.
+func SetupSftpGoUserWebhookWithManager(mgr ctrl.Manager, rejectInlineSecrets bool) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&sftpgov1alpha1.SftpGoUser{}).
+		WithValidator(&SftpGoUserCustomValidator{RejectInlineSecrets: rejectInlineSecrets}).
+		WithDefaulter(&SftpGoUserCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-sftpgo-sftpgo-io-v1alpha1-sftpgouser,mutating=true,failurePolicy=fail,sideEffects=None,groups=sftpgo.sftpgo.io,resources=sftpgousers,verbs=create;update,versions=v1alpha1,name=msftpgouser-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// SftpGoUserCustomDefaulter sets default values on the SftpGoUser resource
+// when it is created or updated.
+type SftpGoUserCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &SftpGoUserCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind SftpGoUser.
+func (d *SftpGoUserCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	user, ok := obj.(*sftpgov1alpha1.SftpGoUser)
+	if !ok {
+		return fmt.Errorf("expected an SftpGoUser object but got %T", obj)
+	}
+	sftpgouserlog.V(1).Info("Defaulting", "name", user.GetName())
+
+	if user.Spec.Status == "" {
+		user.Spec.Status = "enabled"
+	}
+	if policy := user.Spec.HomeDirPolicy; policy != nil && policy.OnDelete == "" {
+		policy.OnDelete = "Keep"
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-sftpgo-sftpgo-io-v1alpha1-sftpgouser,mutating=false,failurePolicy=fail,sideEffects=None,groups=sftpgo.sftpgo.io,resources=sftpgousers,verbs=create;update,versions=v1alpha1,name=vsftpgouser-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// SftpGoUserCustomValidator validates the SftpGoUser resource when it is
+// created or updated.
+type SftpGoUserCustomValidator struct {
+	// RejectInlineSecrets refuses secrets set in clear text in the spec
+	// instead of warning about them
+	RejectInlineSecrets bool
+}
+
+var _ webhook.CustomValidator = &SftpGoUserCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type SftpGoUser.
+func (v *SftpGoUserCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	user, ok := obj.(*sftpgov1alpha1.SftpGoUser)
+	if !ok {
+		return nil, fmt.Errorf("expected an SftpGoUser object but got %T", obj)
+	}
+	return v.validate(user)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type SftpGoUser.
+func (v *SftpGoUserCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	user, ok := newObj.(*sftpgov1alpha1.SftpGoUser)
+	if !ok {
+		return nil, fmt.Errorf("expected an SftpGoUser object for the newObj but got %T", newObj)
+	}
+	return v.validate(user)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type SftpGoUser.
+func (v *SftpGoUserCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate returns an Invalid error listing the fields SFTPGO would refuse,
+// and the inline secrets when they are rejected. Allowed inline secrets are
+// returned as warnings.
+func (v *SftpGoUserCustomValidator) validate(user *sftpgov1alpha1.SftpGoUser) (admission.Warnings, error) {
+	var warnings admission.Warnings
+	allErrs := validateSftpGoUserSpec(&user.Spec, field.NewPath("spec"))
+	for _, fldPath := range inlineSecretPaths(&user.Spec, field.NewPath("spec")) {
+		if v.RejectInlineSecrets {
+			// The value is a secret, never echo it
+			allErrs = append(allErrs, field.Forbidden(fldPath, "inline secrets are not accepted, reference a Secret instead"))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s is stored in clear text, reference a Secret instead", fldPath))
+		}
+	}
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(sftpgov1alpha1.GroupVersion.WithKind("SftpGoUser").GroupKind(), user.Name, allErrs)
+}
+
+// inlineSecretPaths returns the fields of the spec holding a secret in clear
+// text
+func inlineSecretPaths(spec *sftpgov1alpha1.SftpGoUserSpec, fldPath *field.Path) []*field.Path {
+	var out []*field.Path
+	if spec.Password != "" {
+		out = append(out, fldPath.Child("password"))
+	}
+	if fs := spec.Filesystem; fs != nil && fs.Azure != nil && fs.Azure.SASURL != "" {
+		out = append(out, fldPath.Child("filesystem", "azure", "sasURL"))
+	}
+	return out
+}
+
+func validateSftpGoUserSpec(spec *sftpgov1alpha1.SftpGoUserSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.Password != "" && spec.PasswordSecretRef != nil {
+		// The value is a secret, never echo it
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("password"), "password and passwordSecretRef are mutually exclusive"))
+	}
+	if spec.PasswordHashSecretRef != nil && (spec.Password != "" || spec.PasswordSecretRef != nil) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("passwordHashSecretRef"), "cannot be used with password or passwordSecretRef"))
+	}
+	if gen := spec.GeneratedCredentials; gen != nil && gen.Password != nil &&
+		(spec.Password != "" || spec.PasswordSecretRef != nil || spec.PasswordHashSecretRef != nil) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("generatedCredentials", "password"),
+			"cannot be used with password, passwordSecretRef or passwordHashSecretRef"))
+	}
+	if fs := spec.Filesystem; fs != nil && fs.Azure != nil && fs.Azure.SASURL != "" && fs.Azure.SASURLSecretRef != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("filesystem", "azure", "sasURL"), "sasURL and sasURLSecretRef are mutually exclusive"))
+	}
+	if !path.IsAbs(spec.HomeDir) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("homeDir"), spec.HomeDir, "must be an absolute path"))
+	}
+	for i, key := range spec.PublicKeys {
+		if err := validatePublicKey(key); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("publicKeys").Index(i), key, err.Error()))
+		}
+	}
+	allErrs = append(allErrs, validateCIDRs(fldPath.Child("allowedIP"), spec.AllowedIP)...)
+	allErrs = append(allErrs, validateCIDRs(fldPath.Child("deniedIP"), spec.DeniedIP)...)
+
+	allErrs = append(allErrs, validatePermissions(fldPath.Child("permissions"), spec.Permissions)...)
+	for i, dp := range spec.DirectoryPermissions {
+		dpPath := fldPath.Child("directoryPermissions").Index(i)
+		if !path.IsAbs(dp.Path) || path.Clean(dp.Path) != dp.Path {
+			allErrs = append(allErrs, field.Invalid(dpPath.Child("path"), dp.Path, "must be an absolute and clean path"))
+		}
+		allErrs = append(allErrs, validatePermissions(dpPath.Child("permissions"), dp.Permissions)...)
+		if dp.Path == "/" && len(dp.Permissions) > 0 && len(spec.Permissions) > 0 {
+			allErrs = append(allErrs, field.Forbidden(dpPath.Child("permissions"), "the permissions of / are set by spec.permissions"))
+		}
+	}
+
+	for i, ti := range spec.Filters.TimeIntervals {
+		// A start after the end spans midnight, only equal hours are empty
+		if ti.Start == ti.End && ti.Start != 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("filters", "timeIntervals").Index(i).Child("end"), ti.End,
+				"must differ from start, the interval is empty"))
+		}
+	}
+	return allErrs
+}
+
+// validatePermissions checks the permissions against the SFTPGO vocabulary
+func validatePermissions(fldPath *field.Path, perms []string) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, perm := range perms {
+		if len(sftpgo.UnknownPermissions([]string{perm})) > 0 {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Index(i), perm, sftpgo.PermissionNames()))
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sftpgov1alpha1 "github.com/sftpgo/sftpgo-operator/api/v1alpha1"
+)
+
+// ed25519Key is a well-formed authorized_keys entry
+const ed25519Key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAABAgMEBQYHCAkKCwwNDg8QERITFBUWFxgZGhscHR4f alice@example"
+
+var _ = Describe("SftpGoUser Webhook", func() {
+	var (
+		ctx       context.Context
+		user      *sftpgov1alpha1.SftpGoUser
+		validator SftpGoUserCustomValidator
+		defaulter SftpGoUserCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		validator = SftpGoUserCustomValidator{}
+		user = &sftpgov1alpha1.SftpGoUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "default"},
+			Spec: sftpgov1alpha1.SftpGoUserSpec{
+				Username:  "alice",
+				HomeDir:   "/srv/sftpgo/data/alice",
+				ServerRef: sftpgov1alpha1.ServerRef{Name: "files"},
+			},
+		}
+	})
+
+	Context("When creating SftpGoUser under Defaulting Webhook", func() {
+		It("enables the user and keeps its home directory by default", func() {
+			user.Spec.HomeDirPolicy = &sftpgov1alpha1.HomeDirPolicy{}
+			Expect(defaulter.Default(ctx, user)).To(Succeed())
+			Expect(user.Spec.Status).To(Equal("enabled"))
+			Expect(user.Spec.HomeDirPolicy.OnDelete).To(Equal("Keep"))
+
+			user.Spec.Status = "disabled"
+			Expect(defaulter.Default(ctx, user)).To(Succeed())
+			Expect(user.Spec.Status).To(Equal("disabled"))
+		})
+	})
+
+	Context("When creating or updating SftpGoUser under Validating Webhook", func() {
+		It("admits a valid user", func() {
+			user.Spec.PublicKeys = []string{ed25519Key}
+			user.Spec.AllowedIP = []string{"10.0.0.0/8", "2001:db8::/32"}
+			user.Spec.Permissions = []string{"list", "download"}
+			user.Spec.Filters.TimeIntervals = []sftpgov1alpha1.TimeInterval{{Start: 22, End: 6}}
+			Expect(validator.ValidateCreate(ctx, user)).Error().NotTo(HaveOccurred())
+			Expect(validator.ValidateUpdate(ctx, user, user)).Error().NotTo(HaveOccurred())
+		})
+
+		It("reports each invalid field", func() {
+			user.Spec.Password = "s3cret"
+			user.Spec.PasswordSecretRef = &sftpgov1alpha1.SecretRef{Name: "alice", Key: "password"}
+			user.Spec.HomeDir = "data/alice"
+			user.Spec.PublicKeys = []string{ed25519Key, "ssh-rsa AAAAC3NzaC1lZDI1NTE5AAAAIAABAgMEBQYHCAkKCwwNDg8QERITFBUWFxgZGhscHR4f", "not a key"}
+			user.Spec.AllowedIP = []string{"10.0.0.1"}
+			user.Spec.DeniedIP = []string{"10.0.0.0/33"}
+			user.Spec.Permissions = []string{"*", "read"}
+			user.Spec.DirectoryPermissions = []sftpgov1alpha1.DirectoryPermissions{
+				{Path: "/", Permissions: []string{"list"}},
+				{Path: "/in/../out", Permissions: []string{"write"}},
+			}
+			user.Spec.Filters.TimeIntervals = []sftpgov1alpha1.TimeInterval{{Start: 8, End: 8}}
+
+			_, err := validator.ValidateCreate(ctx, user)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			var fields []string
+			for _, cause := range err.(*apierrors.StatusError).ErrStatus.Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			Expect(fields).To(ConsistOf(
+				"spec.password",
+				"spec.homeDir",
+				"spec.publicKeys[1]",
+				"spec.publicKeys[2]",
+				"spec.allowedIP[0]",
+				"spec.deniedIP[0]",
+				"spec.permissions[1]",
+				"spec.directoryPermissions[0].permissions",
+				"spec.directoryPermissions[1].path",
+				"spec.directoryPermissions[1].permissions[0]",
+				"spec.filters.timeIntervals[0].end",
+			))
+			Expect(err.Error()).NotTo(ContainSubstring("s3cret"))
+		})
+
+		It("keeps a password hash apart from the other passwords", func() {
+			user.Spec.PasswordHashSecretRef = &sftpgov1alpha1.SecretRef{Name: "alice", Key: "hash"}
+			Expect(validator.ValidateCreate(ctx, user)).Error().NotTo(HaveOccurred())
+
+			user.Spec.PasswordSecretRef = &sftpgov1alpha1.SecretRef{Name: "alice", Key: "password"}
+			user.Spec.GeneratedCredentials = &sftpgov1alpha1.GeneratedCredentials{Password: &sftpgov1alpha1.PasswordPolicy{}}
+			_, err := validator.ValidateCreate(ctx, user)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			var fields []string
+			for _, cause := range err.(*apierrors.StatusError).ErrStatus.Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			Expect(fields).To(ConsistOf("spec.passwordHashSecretRef", "spec.generatedCredentials.password"))
+		})
+
+		It("warns about inline secrets, or rejects them when asked to", func() {
+			user.Spec.Password = "s3cret"
+			user.Spec.Filesystem = &sftpgov1alpha1.FilesystemConfig{
+				Provider: "azureblob",
+				Azure:    &sftpgov1alpha1.AzureFilesystemConfig{SASURL: "https://example.blob.core.windows.net/?sig=x"},
+			}
+			warnings, err := validator.ValidateCreate(ctx, user)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(
+				"spec.password is stored in clear text, reference a Secret instead",
+				"spec.filesystem.azure.sasURL is stored in clear text, reference a Secret instead",
+			))
+
+			validator.RejectInlineSecrets = true
+			_, err = validator.ValidateUpdate(ctx, user, user)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.filesystem.azure.sasURL"))
+			Expect(err.Error()).NotTo(ContainSubstring("s3cret"))
+			Expect(err.Error()).NotTo(ContainSubstring("sig=x"))
+
+			user.Spec.Password = ""
+			user.Spec.PasswordSecretRef = &sftpgov1alpha1.SecretRef{Name: "alice", Key: "password"}
+			user.Spec.Filesystem.Azure.SASURL = ""
+			user.Spec.Filesystem.Azure.SASURLSecretRef = &sftpgov1alpha1.SecretRef{Name: "alice", Key: "sasURL"}
+			Expect(validator.ValidateCreate(ctx, user)).To(BeEmpty())
+		})
+	})
+
+	It("parses authorized_keys public keys", func() {
+		Expect(validatePublicKey(ed25519Key)).To(Succeed())
+		Expect(validatePublicKey("ssh-ed25519")).To(MatchError(ContainSubstring("<type> <base64 key>")))
+		Expect(validatePublicKey("ssh-foo AAAA")).To(MatchError(ContainSubstring("unsupported key type")))
+		Expect(validatePublicKey("ssh-rsa !!!")).To(MatchError(ContainSubstring("not base64")))
+		Expect(validatePublicKey("ssh-rsa AAAA")).To(MatchError(ContainSubstring("truncated")))
+		Expect(validatePublicKey("ssh-rsa AAAAB3NzaC1yc2EAAAABeA==")).To(Succeed())
+	})
+})
@@ -0,0 +1,97 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// publicKeyTypes are the SSH public key algorithms SFTPGO accepts
+var publicKeyTypes = map[string]bool{
+	"ssh-rsa":                            true,
+	"ssh-dss":                            true,
+	"ssh-ed25519":                        true,
+	"ecdsa-sha2-nistp256":                true,
+	"ecdsa-sha2-nistp384":                true,
+	"ecdsa-sha2-nistp521":                true,
+	"sk-ssh-ed25519@openssh.com":         true,
+	"sk-ecdsa-sha2-nistp256@openssh.com": true,
+}
+
+// validateCIDRs checks that every value is a CIDR network
+func validateCIDRs(fldPath *field.Path, values []string) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, v := range values {
+		if _, _, err := net.ParseCIDR(v); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), v, "must be a CIDR network (e.g. 192.168.1.0/24)"))
+		}
+	}
+	return allErrs
+}
+
+// validateIPsOrCIDRs checks that every value is an IP address or a CIDR network
+func validateIPsOrCIDRs(fldPath *field.Path, values []string) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, v := range values {
+		if net.ParseIP(v) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(v); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), v, "must be an IP address or a CIDR network"))
+		}
+	}
+	return allErrs
+}
+
+// validatePort checks an optional port number
+func validatePort(fldPath *field.Path, port int32) field.ErrorList {
+	if port < 0 || port > 65535 {
+		return field.ErrorList{field.Invalid(fldPath, port, "must be between 1 and 65535")}
+	}
+	return nil
+}
+
+// validatePublicKey checks that key is an authorized_keys public key,
+// "<type> <base64 key> [comment]", whose data holds a key of that type
+func validatePublicKey(key string) error {
+	fields := strings.Fields(key)
+	if len(fields) < 2 {
+		return fmt.Errorf("must be \"<type> <base64 key> [comment]\"")
+	}
+	if !publicKeyTypes[fields[0]] {
+		return fmt.Errorf("unsupported key type %q", fields[0])
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return fmt.Errorf("key data is not base64: %v", err)
+	}
+	// The key data starts with the key type as an SSH string
+	if len(blob) < 4 {
+		return fmt.Errorf("key data is truncated")
+	}
+	n := binary.BigEndian.Uint32(blob)
+	if uint64(len(blob)) < 4+uint64(n) || string(blob[4:4+n]) != fields[0] {
+		return fmt.Errorf("key data is not a %s key", fields[0])
+	}
+	return nil
+}